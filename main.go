@@ -6,15 +6,34 @@ import (
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/cli"
 	"github.com/simplesmentemat/stealth-grid-cli/pkg/config"
 	"github.com/simplesmentemat/stealth-grid-cli/pkg/model"
 	"github.com/simplesmentemat/stealth-grid-cli/pkg/tui"
 )
 
 func main() {
-	err := config.InitConfig()
+	opts, args, err := cli.ParseGlobalFlags(os.Args[1:])
 	if err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	// The API key is only required by the commands calling the GRID API, so that offline
+	// commands also run in CI jobs and containers without one.
+	if err := config.LoadConfig(opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// The interactive user interface only starts without a command, so that a mistyped
+	// command fails with a usage error instead of opening it inside a pipeline.
+	if len(args) > 0 {
+		os.Exit(cli.Run(args))
+	}
+
+	if err := config.RequireAPIKey(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 
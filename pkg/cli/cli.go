@@ -0,0 +1,165 @@
+// Package cli implements the headless commands of the Stealth Grid CLI.
+//
+// Commands are invoked as "stealth-grid-cli <command> [flags]" and run without the
+// interactive user interface, so they can be used from scripts, servers and CI jobs.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/config"
+)
+
+// Command represents a headless command.
+type Command struct {
+	Name    string                    // Name is the name used to invoke the command.
+	Summary string                    // Summary is a one-line description of the command.
+	Run     func(args []string) error // Run executes the command with the arguments following its name.
+}
+
+// commands returns the available headless commands.
+func commands() []*Command {
+	return []*Command{
+		waitCommand,
+		summarizeCommand,
+		validateCommand,
+		parquetCommand,
+		ingestCommand,
+		sqlCommand,
+		filterCommand,
+		replayCommand,
+		roflCommand,
+		draftCommand,
+		economyCommand,
+		heatmapCommand,
+		redactCommand,
+		exportCommand,
+		seriesCommand,
+	}
+}
+
+// Run executes the headless command named by the first argument.
+//
+// Parameters:
+//   - args: The command line arguments, without the program name.
+//
+// Returns:
+//   - int: The exit code of the command: 0 on success, 2 on usage errors and 1 on any other failure.
+func Run(args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(os.Stdout)
+		return 0
+	}
+
+	for _, c := range commands() {
+		if c.Name != args[0] {
+			continue
+		}
+		err := c.Run(args[1:])
+		if err == nil {
+			return 0
+		}
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "%s: %v\n", c.Name, err)
+		var usageErr usageError
+		if errors.As(err, &usageErr) {
+			return 2
+		}
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+	usage(os.Stderr)
+	return 2
+}
+
+// ParseGlobalFlags parses the flags preceding the command name, which configure the
+// application for the TUI and every command, e.g. "stealth-grid-cli --api-key KEY series ...".
+//
+// Parameters:
+//   - args: The command line arguments, without the program name.
+//
+// Returns:
+//   - config.Options: The settings given with the flags, overriding the environment and the configuration file.
+//   - []string: The remaining arguments, starting with the command name if any.
+//   - error: A usage error if a flag is invalid.
+func ParseGlobalFlags(args []string) (config.Options, []string, error) {
+	var opts config.Options
+	if len(args) > 0 && (args[0] == "help" || args[0] == "-h" || args[0] == "--help") {
+		return opts, args, nil
+	}
+	fs := newFlagSet("stealth-grid-cli")
+	fs.SetOutput(io.Discard)
+	registerGlobalFlags(fs, &opts)
+	if err := fs.Parse(args); err != nil {
+		return opts, nil, usageError(err.Error())
+	}
+	return opts, fs.Args(), nil
+}
+
+// registerGlobalFlags defines the global flags on a flag set.
+func registerGlobalFlags(fs *flag.FlagSet, opts *config.Options) {
+	fs.StringVar(&opts.Path, "config", "", "path of the configuration file (env "+config.EnvConfigPath+")")
+	fs.StringVar(&opts.APIKey, "api-key", "", "GRID API key (env "+config.EnvAPIKey+")")
+	fs.StringVar(&opts.APIURL, "api-url", "", "base URL of the GRID API (env "+config.EnvAPIURL+")")
+	fs.StringVar(&opts.Profile, "profile", "", "name of the profile of the configuration file to use (env "+config.EnvProfile+")")
+}
+
+// usage writes the list of available commands.
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: stealth-grid-cli [global flags] [command] [flags]")
+	fmt.Fprintln(w, "\nWithout a command, the interactive user interface is started.")
+	fmt.Fprintln(w, "\nGlobal flags, which take precedence over the environment and the configuration file:")
+	fs := flag.NewFlagSet("stealth-grid-cli", flag.ContinueOnError)
+	registerGlobalFlags(fs, &config.Options{})
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+	fmt.Fprintln(w, "\nCommands:")
+	for _, c := range commands() {
+		fmt.Fprintf(w, "  %-12s %s\n", c.Name, c.Summary)
+	}
+	fmt.Fprintln(w, "\nRun 'stealth-grid-cli <command> -h' for the flags of a command.")
+}
+
+// usageError reports invalid command line arguments.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+// newFlagSet creates the flag set of a command.
+//
+// Parsing errors are returned instead of exiting, and are reported as usage errors by Run.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+// parseFlags parses the arguments of a command, wrapping parsing errors as usage errors.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError(err.Error())
+	}
+	return nil
+}
+
+// splitList splits a comma separated flag value, ignoring empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value    string
+		expected time.Time
+	}{
+		{"now", now},
+		{"-7d", now.Add(-7 * 24 * time.Hour)},
+		{"+12h", now.Add(12 * time.Hour)},
+		{"-30m", now.Add(-30 * time.Minute)},
+		{" +1d ", now.Add(24 * time.Hour)},
+		{"2024-05-10", time.Date(2024, 5, 10, 0, 0, 0, 0, time.Local)},
+		{"2024-05-10T14:30:00+02:00", time.Date(2024, 5, 10, 12, 30, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		got, err := parseTime(test.value, now)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", test.value, err)
+		}
+		if !got.Equal(test.expected) {
+			t.Fatalf("Expected %q to be %s, but got %s", test.value, test.expected, got)
+		}
+	}
+
+	for _, value := range []string{"7d", "+", "-d", "+7w", "yesterday", "2024-13-01"} {
+		if _, err := parseTime(value, now); err == nil {
+			t.Fatalf("Expected an error for %q", value)
+		}
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.zip")
+	tests := []struct {
+		args     []string
+		expected int
+	}{
+		{[]string{"help"}, 0},
+		{[]string{"validate", "-h"}, 0},
+		{[]string{"unknown"}, 2},
+		{[]string{"validate", "-unknown-flag"}, 2},
+		{[]string{"validate", "-format", "xml"}, 2},
+		{[]string{"series", "-title", "3", "-from", "7d"}, 2},
+		{[]string{"series", "-title", "3", "-from", "now", "-to", "-1d"}, 2},
+		{[]string{"validate", "-file", missing}, 1},
+	}
+	for _, test := range tests {
+		if code := Run(test.args); code != test.expected {
+			t.Fatalf("Expected exit code %d for %v, but got %d", test.expected, test.args, code)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/draft"
+)
+
+var draftCommand = &Command{
+	Name:    "draft",
+	Summary: "Print the picks and bans of a downloaded series",
+	Run:     runDraft,
+}
+
+// runDraft prints the draft actions of a series as a table, CSV or JSON.
+func runDraft(args []string) error {
+	fs := newFlagSet("draft")
+	seriesID := fs.String("series", "", "ID of a series whose events archive is in the download library")
+	file := fs.String("file", "", "path of an events archive")
+	format := fs.String("format", "table", "output format: 'table', 'csv' or 'json'")
+	out := fs.String("out", "", "write the draft to this file instead of the standard output")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *format != "table" && *format != "csv" && *format != "json" {
+		return usageError(fmt.Sprintf("unknown format %q", *format))
+	}
+
+	path, err := archivePath(*seriesID, *file)
+	if err != nil {
+		return err
+	}
+	d, err := draft.ExtractArchive(path)
+	if err != nil {
+		return err
+	}
+
+	var output io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("error creating file: %v", err)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	switch *format {
+	case "table":
+		if len(d.Actions) == 0 {
+			fmt.Fprintln(output, "No draft actions found in the events.")
+			return nil
+		}
+		return printTable(output, draft.Headers, d.Rows())
+	case "csv":
+		writer := csv.NewWriter(output)
+		if err := writer.Write(draft.Headers); err != nil {
+			return err
+		}
+		return writer.WriteAll(d.Rows())
+	case "json":
+		enc := json.NewEncoder(output)
+		enc.SetIndent("", "  ")
+		return enc.Encode(d)
+	}
+	return nil
+}
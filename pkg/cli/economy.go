@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/economy"
+)
+
+var economyCommand = &Command{
+	Name:    "economy",
+	Summary: "Print the round by round economy of a downloaded CS2 or Valorant series",
+	Run:     runEconomy,
+}
+
+// runEconomy prints the buy summary and the round by round economy of a series and
+// optionally exports the rounds to a CSV file.
+func runEconomy(args []string) error {
+	fs := newFlagSet("economy")
+	seriesID := fs.String("series", "", "ID of a series whose events archive is in the download library")
+	file := fs.String("file", "", "path of an events archive")
+	titleID := fs.String("title", "", "title ID of the series, 28 for CS2 or 6 for Valorant (default: from the library)")
+	out := fs.String("out", "", "export the round by round economy to this CSV file")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	entries, err := libraryArchives(*seriesID, *file, false)
+	if err != nil {
+		return err
+	}
+	entry := entries[0]
+	title := entry.Series.TitleID
+	if *titleID != "" {
+		title = *titleID
+	}
+	if title == "" {
+		return usageError("the title of the series is unknown; set it with -title")
+	}
+
+	e, err := economy.ExtractArchive(entry.Path, title)
+	if err != nil {
+		return err
+	}
+	if len(e.Rounds) == 0 {
+		fmt.Println("No rounds found in the events.")
+		return nil
+	}
+
+	if err := printTable(os.Stdout, economy.SummaryHeaders, e.SummaryRows()); err != nil {
+		return err
+	}
+	fmt.Println()
+	if err := printTable(os.Stdout, economy.Headers, e.Rows()); err != nil {
+		return err
+	}
+	if *out != "" {
+		return writeCSVFile(*out, economy.Headers, e.Rows())
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/config"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/export"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/graphql"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/warehouse"
+)
+
+var exportCommand = &Command{
+	Name:    "export",
+	Summary: "Export the series of a title from the GRID API as data, a calendar or a schedule report",
+	Run:     runExport,
+}
+
+// runExport fetches the series of a title over a range of days and exports them.
+func runExport(args []string) error {
+	fs := newFlagSet("export")
+	titleID := fs.String("title", config.GetDefaultTitle(), "title ID of the series to export (default: the default title of the profile)")
+	pastDays := fs.Int("past-days", 7, "number of past days of series to export")
+	futureDays := fs.Int("future-days", 1, "number of future days of series to export")
+	formatName := fs.String("format", "csv", "output format: 'csv', 'json', 'ndjson', 'xlsx', 'ics', 'md' and 'html' for schedule reports, or 'sqlite' to update a database")
+	out := fs.String("out", "", "write the series to this file instead of the standard output; required with -format sqlite")
+	columnKeys := fs.String("columns", "", "with -format csv or xlsx, comma-separated columns of the series, e.g. start_time,series_id,format (default: the configured columns)")
+	sheetBy := fs.String("sheet-by", "", "with -format xlsx, write one sheet per value of this column, e.g. Tournament")
+	tz := fs.String("tz", "", "with -format xlsx, md or html, timezone of the dates, e.g. Europe/Paris (default: the local timezone)")
+	title := fs.String("report-title", "", "with -format md or html, title of the report (default: Series schedule)")
+	templatePath := fs.String("template", "", "with -format md or html, path of a custom template of the report (default: the configured template)")
+	gameDuration := fs.Duration("game-duration", time.Hour, "with -format ics, estimated duration of a game, multiplied by the best-of of the series")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	loc := time.Local
+	if *tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(*tz); err != nil {
+			return usageError(fmt.Sprintf("unknown timezone %q", *tz))
+		}
+	}
+	if *titleID == "" {
+		return usageError("the -title flag is required")
+	}
+	format, err := export.ParseFormat(*formatName)
+	if err != nil {
+		return usageError(err.Error())
+	}
+	if format == export.SQLite && *out == "" {
+		return usageError("the -out flag is required with -format sqlite")
+	}
+	keys := config.GetSeriesColumns()
+	if *columnKeys != "" {
+		keys = splitList(*columnKeys)
+	}
+	columns, err := export.ParseColumns(keys)
+	if err != nil {
+		return usageError(err.Error())
+	}
+
+	if err := config.RequireAPIKey(); err != nil {
+		return err
+	}
+	startTime := time.Now().Add(time.Duration(-*pastDays) * 24 * time.Hour)
+	endTime := time.Now().Add(time.Duration(*futureDays) * 24 * time.Hour)
+	series, err := graphql.FetchAllSeries(*titleID, startTime, endTime)
+	if err != nil {
+		return err
+	}
+	if export.NeedsResults(columns) {
+		if err := warehouse.LoadResults(series); err != nil {
+			return err
+		}
+	}
+
+	if format == export.SQLite {
+		path := export.Path(*out, format)
+		result, err := export.UpsertSQLite(path, series, export.SQLiteOptions{})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d series exported to %s: %d new, %d updated (%d rescheduled), %d unchanged\n",
+			len(series), path, result.Inserted, result.Updated, result.Rescheduled, result.Unchanged)
+		return nil
+	}
+
+	write := func(w io.Writer) error {
+		if format == export.Markdown || format == export.HTML {
+			path := *templatePath
+			if path == "" {
+				path = config.GetReportTemplate(export.TemplateName(format))
+			}
+			tmpl, err := export.ReadTemplate(path)
+			if err != nil {
+				return err
+			}
+			return export.WriteReport(w, series, format, export.ReportOptions{Title: *title, Location: loc, Template: tmpl})
+		}
+		if format == export.ICS {
+			return export.WriteICS(w, series, export.ICSOptions{GameDuration: *gameDuration})
+		}
+		if format != export.XLSX {
+			return export.ExportSeriesColumns(w, series, columns, format)
+		}
+		headers, rows := export.Headers(columns), export.ColumnRows(series, columns)
+		sheets := []export.Sheet{{Name: "Series", Headers: headers, Rows: rows}}
+		if *sheetBy != "" {
+			var err error
+			if sheets, err = export.SplitSheets(headers, rows, *sheetBy); err != nil {
+				return err
+			}
+		}
+		return export.WriteXLSX(w, sheets, export.XLSXOptions{Location: loc})
+	}
+	if *out == "" {
+		return write(os.Stdout)
+	}
+	path, err := export.WriteFile(*out, format, write)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d series exported to %s\n", len(series), path)
+	return nil
+}
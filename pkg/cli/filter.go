@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/filter"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/summary"
+)
+
+var filterCommand = &Command{
+	Name:    "filter",
+	Summary: "Print the events of a downloaded archive matching a filter expression",
+	Run:     runFilter,
+}
+
+// runFilter streams the events of an archive and prints those matching the expression given
+// as arguments, as JSON lines or as a table.
+func runFilter(args []string) error {
+	fs := newFlagSet("filter")
+	seriesID := fs.String("series", "", "ID of a series whose events archive is in the download library")
+	file := fs.String("file", "", "path of an events archive")
+	format := fs.String("format", "jsonl", "output format: 'jsonl' or 'table'")
+	limit := fs.Int("limit", 0, "stop after this number of matching events, 0 for no limit")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: stealth-grid-cli filter [flags] [expression]")
+		fmt.Fprintln(fs.Output(), "\nExample: stealth-grid-cli filter -series 2620066 'type=*-killed-dragon game=3 since=10:00'")
+		fmt.Fprintln(fs.Output(), "\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *format != "jsonl" && *format != "table" {
+		return usageError(fmt.Sprintf("unknown format %q", *format))
+	}
+
+	f, err := filter.Parse(strings.Join(fs.Args(), " "))
+	if err != nil {
+		return usageError(err.Error())
+	}
+	path, err := archivePath(*seriesID, *file)
+	if err != nil {
+		return err
+	}
+	archive, err := events.Open(path)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	enc := json.NewEncoder(os.Stdout)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if *format == "table" {
+		fmt.Fprintln(tw, "Seq\tOccurred At\tGame\tGame Time\tType\tActor\tTarget")
+	}
+
+	count := 0
+	for m, err := range f.Apply(archive.Events()) {
+		if err != nil {
+			return err
+		}
+		if *format == "jsonl" {
+			if err := enc.Encode(m); err != nil {
+				return err
+			}
+		} else {
+			game, gameTime := "", ""
+			if m.GameNumber > 0 {
+				game = strconv.Itoa(m.GameNumber)
+				gameTime = summary.FormatDuration(m.GameTime)
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", m.SequenceNumber, m.OccurredAt.Format("2006-01-02T15:04:05Z07:00"),
+				game, gameTime, m.Type, m.Actor.Label(), m.Target.Label())
+		}
+		count++
+		if *limit > 0 && count >= *limit {
+			break
+		}
+	}
+	if *format == "table" {
+		return tw.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/filter"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/heatmap"
+)
+
+var heatmapCommand = &Command{
+	Name:    "heatmap",
+	Summary: "Render a heatmap PNG of the player positions of a downloaded series",
+	Run:     runHeatmap,
+}
+
+// unsafeFileChars matches the characters replaced in the file names of split heatmaps.
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// runHeatmap aggregates the positions of the players of an archive, selected by game, team,
+// player and game time, and renders them to one PNG file or one file per team or player.
+func runHeatmap(args []string) error {
+	fs := newFlagSet("heatmap")
+	seriesID := fs.String("series", "", "ID of a series whose events archive is in the download library")
+	file := fs.String("file", "", "path of an events archive")
+	out := fs.String("out", "heatmap.png", "path of the PNG file; with -by, the team or player name is appended to it")
+	by := fs.String("by", "all", "render one heatmap for 'all' positions, or one per 'team' or 'player'")
+	bounds := fs.String("bounds", "", "map coordinates covered by the image as minX,minY,maxX,maxY (default: fitted to the positions)")
+	width := fs.Int("width", 1024, "width of the image in pixels")
+	height := fs.Int("height", 1024, "height of the image in pixels")
+	radius := fs.Int("radius", 12, "radius in pixels over which each position is spread")
+	background := fs.String("background", "", "PNG image of the map drawn below the heatmap")
+	games := fs.String("game", "", "comma separated numbers of the games to include (default: all)")
+	teams := fs.String("team", "", "comma separated IDs or names of the teams to include (default: all)")
+	players := fs.String("player", "", "comma separated IDs or names of the players to include (default: all)")
+	since := fs.String("since", "", "include positions from this game time, e.g. 14:00")
+	until := fs.String("until", "", "include positions up to this game time, e.g. 20:00")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *by != "all" && *by != "team" && *by != "player" {
+		return usageError(fmt.Sprintf("unknown grouping %q", *by))
+	}
+	if *width <= 0 || *height <= 0 {
+		return usageError("the image size must be positive")
+	}
+
+	var gameNumbers []int
+	for _, g := range splitList(*games) {
+		n, err := strconv.Atoi(g)
+		if err != nil {
+			return usageError(fmt.Sprintf("invalid game number %q", g))
+		}
+		gameNumbers = append(gameNumbers, n)
+	}
+	from, to := time.Duration(0), time.Duration(-1)
+	if *since != "" {
+		d, err := filter.ParseGameTime(*since)
+		if err != nil {
+			return usageError(err.Error())
+		}
+		from = d
+	}
+	if *until != "" {
+		d, err := filter.ParseGameTime(*until)
+		if err != nil {
+			return usageError(err.Error())
+		}
+		to = d
+	}
+
+	opts := heatmap.Options{Width: *width, Height: *height, Radius: *radius}
+	if *background != "" {
+		img, err := heatmap.ReadImage(*background)
+		if err != nil {
+			return err
+		}
+		opts.Background = img
+	}
+
+	path, err := archivePath(*seriesID, *file)
+	if err != nil {
+		return err
+	}
+	archive, err := events.Open(path)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	teamList, playerList := splitList(*teams), splitList(*players)
+	groups := map[string][]heatmap.Point{}
+	var order []string
+	var all []heatmap.Point
+	for p, err := range heatmap.Positions(archive.Events()) {
+		if err != nil {
+			return err
+		}
+		if len(gameNumbers) > 0 && !containsNumber(gameNumbers, p.GameNumber) {
+			continue
+		}
+		if p.GameTime < from || (to >= 0 && p.GameTime > to) {
+			continue
+		}
+		if !matchesAny(teamList, p.TeamID, p.Team) || !matchesAny(playerList, p.PlayerID, p.Player) {
+			continue
+		}
+
+		key := ""
+		switch *by {
+		case "team":
+			key = firstNonEmpty(p.Team, p.TeamID, "unknown")
+		case "player":
+			key = firstNonEmpty(p.Player, p.PlayerID)
+		}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], p)
+		all = append(all, p)
+	}
+	if len(all) == 0 {
+		return fmt.Errorf("no player positions found in the events")
+	}
+
+	b := heatmap.BoundsOf(all)
+	if *bounds != "" {
+		if b, err = heatmap.ParseBounds(*bounds); err != nil {
+			return usageError(err.Error())
+		}
+	}
+
+	for _, key := range order {
+		target := *out
+		if key != "" {
+			ext := filepath.Ext(target)
+			target = strings.TrimSuffix(target, ext) + "-" + unsafeFileChars.ReplaceAllString(key, "_") + ext
+		}
+		if err := heatmap.WritePNG(target, heatmap.Render(groups[key], b, opts)); err != nil {
+			return err
+		}
+		fmt.Printf("%s: %d positions\n", target, len(groups[key]))
+	}
+	return nil
+}
+
+// containsNumber reports whether a slice contains a number.
+func containsNumber(numbers []int, n int) bool {
+	for _, v := range numbers {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAny reports whether an ID or a name is in a list of IDs and names; an empty list matches everything.
+func matchesAny(values []string, id, name string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == id || (name != "" && strings.EqualFold(v, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstNonEmpty returns the first non-empty string.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
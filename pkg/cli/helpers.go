@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/graphql"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/library"
+)
+
+// archivePath resolves the events archive a command works on.
+//
+// Parameters:
+//   - seriesID: The ID of a series whose events archive is looked up in the library, if file is empty.
+//   - file: The path of an events archive given explicitly.
+//
+// Returns:
+//   - string: The path of the events archive.
+//   - error: A usage error if neither is given, or an error if the series has no downloaded events.
+func archivePath(seriesID, file string) (string, error) {
+	if file != "" {
+		return file, nil
+	}
+	if seriesID == "" {
+		return "", usageError("either the -series or the -file flag is required")
+	}
+	lib, err := library.Open()
+	if err != nil {
+		return "", err
+	}
+	entry, ok := lib.Find(seriesID, graphql.EventsFileID)
+	if !ok {
+		return "", fmt.Errorf("the events of series %s are not in the download library; download them first or use -file", seriesID)
+	}
+	return entry.Path, nil
+}
+
+// libraryArchives resolves the events archives a command works on, with their library metadata.
+//
+// Parameters:
+//   - seriesID: The ID of a series whose events archive is looked up in the library, if file is empty.
+//   - file: The path of an events archive given explicitly.
+//   - all: Whether every events archive of the library is selected.
+//
+// Returns:
+//   - []library.Entry: The selected archives. An archive given by path has the series ID as only metadata.
+//   - error: A usage error if nothing is selected, or an error if no archive is found.
+func libraryArchives(seriesID, file string, all bool) ([]library.Entry, error) {
+	if file != "" {
+		return []library.Entry{{Path: file, FileID: graphql.EventsFileID, Series: library.Series{ID: seriesID}}}, nil
+	}
+	if seriesID == "" && !all {
+		return nil, usageError("one of the -series, -file or -all flags is required")
+	}
+
+	lib, err := library.Open()
+	if err != nil {
+		return nil, err
+	}
+	var entries []library.Entry
+	for _, e := range lib.Entries {
+		if e.FileID == graphql.EventsFileID && (all || e.Series.ID == seriesID) && e.Exists() {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no downloaded events archive found in the library")
+	}
+	return entries, nil
+}
+
+// printTable writes a table aligned in columns.
+func printTable(w io.Writer, headers []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeCSVFile writes a table to a CSV file.
+func writeCSVFile(path string, headers []string, rows [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating file: %v", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(headers); err != nil {
+		return fmt.Errorf("error writing headers to CSV: %v", err)
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("error writing records to CSV: %v", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/config"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/convert"
+)
+
+var parquetCommand = &Command{
+	Name:    "parquet",
+	Summary: "Convert downloaded events archives to a partitioned Parquet dataset",
+	Run:     runParquet,
+}
+
+// runParquet converts one or all events archives of the library, or an archive given by
+// path, to Parquet files partitioned by title, tournament and series.
+func runParquet(args []string) error {
+	fs := newFlagSet("parquet")
+	seriesID := fs.String("series", "", "ID of a series whose events archive is in the download library")
+	file := fs.String("file", "", "path of an events archive")
+	all := fs.Bool("all", false, "convert every events archive of the download library")
+	out := fs.String("out", config.GetPostDownloadParquetDir(), "root directory of the Parquet dataset")
+	titleID := fs.String("title", "", "title ID of the partition, overriding the library metadata")
+	tournament := fs.String("tournament", "", "tournament of the partition, overriding the library metadata")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *out == "" {
+		return usageError("the -out flag is required when post_download.parquet_dir is not configured")
+	}
+
+	entries, err := libraryArchives(*seriesID, *file, *all)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		p := convert.PartitionFor(e.Series)
+		if *titleID != "" {
+			p.TitleID = *titleID
+		}
+		if *tournament != "" {
+			p.Tournament = *tournament
+		}
+		path, count, err := convert.ArchiveToParquet(e.Path, *out, p)
+		if err != nil {
+			return fmt.Errorf("%s: %v", e.Path, err)
+		}
+		fmt.Printf("%s (%d events)\n", path, count)
+	}
+	return nil
+}
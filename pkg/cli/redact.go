@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/config"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/redact"
+)
+
+// redactSecretEnv is the environment variable holding the secret of the redact command.
+const redactSecretEnv = "STEALTH_GRID_REDACT_SECRET"
+
+var redactCommand = &Command{
+	Name:    "redact",
+	Summary: "Pseudonymize the teams and players of an events archive or a CSV export",
+	Run:     runRedact,
+}
+
+// runRedact rewrites an events archive or a CSV file with pseudonyms in place of the team and
+// player identities, and writes the mapping of the pseudonyms to a private file.
+func runRedact(args []string) error {
+	fs := newFlagSet("redact")
+	seriesID := fs.String("series", "", "ID of a series whose events archive is in the download library")
+	file := fs.String("file", "", "path of an events archive or of a CSV file")
+	out := fs.String("out", "", "path of the redacted file (default: the input name with a .redacted suffix)")
+	secret := fs.String("secret", "", "secret key of the pseudonyms (default: the "+redactSecretEnv+" environment variable)")
+	drop := fs.String("drop", strings.Join(config.GetRedactDropFields(), ","), "comma separated names of the event fields or CSV columns to remove")
+	mapping := fs.String("mapping", "", "path of the mapping of the pseudonyms (default: the output name with a .mapping.json suffix)")
+	teamColumns := fs.String("team-columns", "", "comma separated CSV columns holding teams (default: columns mentioning a team or a winner)")
+	playerColumns := fs.String("player-columns", "", "comma separated CSV columns holding players (default: columns mentioning a player)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *secret == "" {
+		*secret = os.Getenv(redactSecretEnv)
+	}
+	if *secret == "" {
+		return usageError("a secret is required: use -secret or set " + redactSecretEnv)
+	}
+
+	path, err := archivePath(*seriesID, *file)
+	if err != nil {
+		return err
+	}
+	ext := filepath.Ext(path)
+	if *out == "" {
+		*out = strings.TrimSuffix(filepath.Base(path), ext) + ".redacted" + ext
+	}
+	if *mapping == "" {
+		*mapping = strings.TrimSuffix(*out, filepath.Ext(*out)) + ".mapping.json"
+	}
+	if filepath.Clean(*out) == filepath.Clean(path) {
+		return usageError("the redacted file must not overwrite its input")
+	}
+
+	r, err := redact.New(*secret, splitList(*drop))
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(ext, ".csv") {
+		err = redactCSV(r, path, *out, splitList(*teamColumns), splitList(*playerColumns))
+	} else {
+		err = r.Archive(path, *out)
+	}
+	if err != nil {
+		return err
+	}
+	if err := r.WriteMapping(*mapping); err != nil {
+		return err
+	}
+	fmt.Printf("Redacted %s to %s; the mapping of the %d pseudonyms is in %s and must be kept private.\n", path, *out, len(r.Mappings()), *mapping)
+	return nil
+}
+
+// redactCSV redacts a CSV file, using the default columns unless team or player columns are given.
+func redactCSV(r *redact.Redactor, in, out string, teams, players []string) error {
+	input, err := os.Open(in)
+	if err != nil {
+		return fmt.Errorf("error opening file: %v", err)
+	}
+	defer input.Close()
+	output, err := os.Create(out)
+	if err != nil {
+		return fmt.Errorf("error creating file: %v", err)
+	}
+	defer output.Close()
+
+	var columns map[string]string
+	if len(teams) > 0 || len(players) > 0 {
+		columns = map[string]string{}
+		for _, c := range teams {
+			columns[c] = redact.Team
+		}
+		for _, c := range players {
+			columns[c] = redact.Player
+		}
+	}
+	if err := r.CSV(input, output, columns, r.Drop); err != nil {
+		return err
+	}
+	return output.Close()
+}
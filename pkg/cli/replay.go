@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/filter"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/replay"
+)
+
+var replayCommand = &Command{
+	Name:    "replay",
+	Summary: "Export snapshots of the reconstructed state of a series at given game times",
+	Run:     runReplay,
+}
+
+// runReplay replays the events of an archive and exports snapshots of the series state as
+// CSV or JSON.
+func runReplay(args []string) error {
+	fs := newFlagSet("replay")
+	seriesID := fs.String("series", "", "ID of a series whose events archive is in the download library")
+	file := fs.String("file", "", "path of an events archive")
+	at := fs.String("at", "", "comma separated snapshot times: game times (e.g. 14:00), taken in every game, or RFC3339 timestamps")
+	every := fs.Duration("every", 0, "take a snapshot at each multiple of this game time in every game (e.g. 1m)")
+	format := fs.String("format", "csv", "output format: 'csv' or 'json'")
+	players := fs.Bool("players", false, "export one CSV row per player instead of one per team")
+	out := fs.String("out", "", "write the snapshots to this file instead of the standard output")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *format != "csv" && *format != "json" {
+		return usageError(fmt.Sprintf("unknown format %q", *format))
+	}
+
+	opts := replay.Options{Every: *every}
+	for _, value := range splitList(*at) {
+		b, err := filter.ParseBound(value)
+		if err != nil {
+			return usageError(err.Error())
+		}
+		opts.At = append(opts.At, b)
+	}
+	if len(opts.At) == 0 && opts.Every <= 0 {
+		return usageError("either the -at or the -every flag is required")
+	}
+
+	path, err := archivePath(*seriesID, *file)
+	if err != nil {
+		return err
+	}
+	archive, err := events.Open(path)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	snapshots, err := replay.Snapshots(archive.Events(), opts)
+	if err != nil {
+		return err
+	}
+
+	var output io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("error creating file: %v", err)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(output)
+		enc.SetIndent("", "  ")
+		return enc.Encode(snapshots)
+	}
+
+	headers, rows := replay.TeamHeaders, replay.TeamRows(snapshots)
+	if *players {
+		headers, rows = replay.PlayerHeaders, replay.PlayerRows(snapshots)
+	}
+	writer := csv.NewWriter(output)
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+	return writer.WriteAll(rows)
+}
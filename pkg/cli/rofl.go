@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/graphql"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/library"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/rofl"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/summary"
+)
+
+var roflCommand = &Command{
+	Name:    "rofl",
+	Summary: "Print the metadata of downloaded League of Legends replays",
+	Run:     runROFL,
+}
+
+// runROFL prints the metadata of replays and writes it to a JSON file next to each replay.
+func runROFL(args []string) error {
+	fs := newFlagSet("rofl")
+	seriesID := fs.String("series", "", "ID of a series whose replays are in the download library")
+	game := fs.String("game", "", "number of the game whose replay is read, with -series (default: every downloaded game)")
+	file := fs.String("file", "", "path of a replay")
+	noSidecar := fs.Bool("no-sidecar", false, "do not write the metadata to a JSON file next to the replay")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	paths, err := replayPaths(*seriesID, *game, *file)
+	if err != nil {
+		return err
+	}
+	for i, path := range paths {
+		if i > 0 {
+			fmt.Println()
+		}
+		m, err := rofl.ParseFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %v", path, err)
+		}
+		fmt.Printf("%s\nGame length: %s, version %s (patch %s)\n\n", path, summary.FormatDuration(m.Duration()), m.GameVersion, m.Patch)
+		if err := printTable(os.Stdout, rofl.PlayerHeaders, m.PlayerRows()); err != nil {
+			return err
+		}
+		if !*noSidecar {
+			sidecar, err := rofl.WriteSidecar(path, m)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("\nMetadata written to %s\n", sidecar)
+		}
+	}
+	return nil
+}
+
+// replayPaths resolves the replays the rofl command works on.
+func replayPaths(seriesID, game, file string) ([]string, error) {
+	if file != "" {
+		return []string{file}, nil
+	}
+	if seriesID == "" {
+		return nil, usageError("either the -series or the -file flag is required")
+	}
+	lib, err := library.Open()
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, e := range lib.Series(seriesID) {
+		if e.FileID != graphql.EventsFileID && (game == "" || e.FileID == game) {
+			paths = append(paths, e.Path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no replay of series %s is in the download library; download it first or use -file", seriesID)
+	}
+	return paths, nil
+}
@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/config"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/export"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/graphql"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/warehouse"
+)
+
+var seriesCommand = &Command{
+	Name:    "series",
+	Summary: "Stream the series of a title from the GRID API to the standard output as they arrive",
+	Run:     runSeries,
+}
+
+// runSeries writes the series of a title to the standard output, page after page.
+func runSeries(args []string) error {
+	fs := newFlagSet("series")
+	titleID := fs.String("title", config.GetDefaultTitle(), "title ID of the series (default: the default title of the profile)")
+	from := fs.String("from", "-7d", "start of the range: 'now', an offset such as -7d or -12h, a date or an RFC 3339 time")
+	to := fs.String("to", "+1d", "end of the range: 'now', an offset such as +1d or +6h, a date or an RFC 3339 time")
+	formatName := fs.String("format", "csv", "output format: 'csv', 'json', 'ndjson', 'xlsx', 'ics', 'md' or 'html'")
+	columnKeys := fs.String("columns", "", "with -format csv or xlsx, comma-separated columns of the series (default: the configured columns)")
+	tz := fs.String("tz", "", "with -format xlsx, md or html, timezone of the dates, e.g. Europe/Paris (default: the local timezone)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *titleID == "" {
+		return usageError("the -title flag is required")
+	}
+	now := time.Now()
+	startTime, err := parseTime(*from, now)
+	if err != nil {
+		return usageError(fmt.Sprintf("invalid -from: %v", err))
+	}
+	endTime, err := parseTime(*to, now)
+	if err != nil {
+		return usageError(fmt.Sprintf("invalid -to: %v", err))
+	}
+	if endTime.Before(startTime) {
+		return usageError("-to must not be before -from")
+	}
+	format, err := export.ParseFormat(*formatName)
+	if err != nil {
+		return usageError(err.Error())
+	}
+	if format == export.SQLite {
+		return usageError("sqlite exports cannot be streamed; use the export command with -out")
+	}
+	keys := config.GetSeriesColumns()
+	if *columnKeys != "" {
+		keys = splitList(*columnKeys)
+	}
+	columns, err := export.ParseColumns(keys)
+	if err != nil {
+		return usageError(err.Error())
+	}
+	loc := time.Local
+	if *tz != "" {
+		if loc, err = time.LoadLocation(*tz); err != nil {
+			return usageError(fmt.Sprintf("unknown timezone %q", *tz))
+		}
+	}
+
+	var report export.ReportOptions
+	if format == export.Markdown || format == export.HTML {
+		tmpl, err := export.ReadTemplate(config.GetReportTemplate(export.TemplateName(format)))
+		if err != nil {
+			return err
+		}
+		report = export.ReportOptions{Location: loc, Template: tmpl}
+	}
+	if err := config.RequireAPIKey(); err != nil {
+		return err
+	}
+	w, err := export.NewSeriesWriter(os.Stdout, format, export.StreamOptions{
+		Columns: columns,
+		XLSX:    export.XLSXOptions{Location: loc},
+		Report:  report,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Each page is flushed before the next one is fetched, so that the series reach the
+	// pipeline as they arrive. Series written before a failure stay written.
+	for page, err := range graphql.SeriesPages(*titleID, startTime, endTime) {
+		if err != nil {
+			w.Flush()
+			return err
+		}
+		if export.NeedsResults(columns) {
+			if err := warehouse.LoadResults(page); err != nil {
+				return err
+			}
+		}
+		for _, s := range page {
+			if err := w.Write(s); err != nil {
+				return err
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// parseTime parses a time flag relative to now.
+//
+// Parameters:
+//   - value: "now", an offset from now made of a sign, a number and a unit among d (days),
+//     h (hours) and m (minutes), e.g. "-7d", a date such as "2024-05-10", or an RFC 3339 time.
+//   - now: The time offsets are relative to.
+//
+// Returns:
+//   - time.Time: The parsed time.
+//   - error: An error if the value is not a valid time.
+func parseTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "now" {
+		return now, nil
+	}
+	if strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-") {
+		units := map[byte]time.Duration{'d': 24 * time.Hour, 'h': time.Hour, 'm': time.Minute}
+		unit, ok := units[value[len(value)-1]]
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if !ok || err != nil {
+			return time.Time{}, fmt.Errorf("%q is not an offset such as -7d, +12h or -30m", value)
+		}
+		return now.Add(time.Duration(n) * unit), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not 'now', an offset, a date or an RFC 3339 time", value)
+	}
+	return t, nil
+}
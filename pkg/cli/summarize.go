@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/summary"
+)
+
+var summarizeCommand = &Command{
+	Name:    "summarize",
+	Summary: "Print the post-match summary of a downloaded series",
+	Run:     runSummarize,
+}
+
+// runSummarize prints the per game and per player summary of a series and optionally
+// exports both tables to CSV files.
+func runSummarize(args []string) error {
+	fs := newFlagSet("summarize")
+	seriesID := fs.String("series", "", "ID of a series whose events archive is in the download library")
+	file := fs.String("file", "", "path of an events archive")
+	out := fs.String("out", "", "export the per game summary to this CSV file")
+	playersOut := fs.String("players-out", "", "export the per player summary to this CSV file")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	path, err := archivePath(*seriesID, *file)
+	if err != nil {
+		return err
+	}
+	s, err := summary.SummarizeArchive(path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Series %s: %s\n\n", s.SeriesID, s.Score())
+	if err := printTable(os.Stdout, summary.TeamHeaders, s.TeamRows()); err != nil {
+		return err
+	}
+	fmt.Println()
+	if err := printTable(os.Stdout, summary.PlayerHeaders, s.PlayerRows()); err != nil {
+		return err
+	}
+
+	if *out != "" {
+		if err := writeCSVFile(*out, summary.TeamHeaders, s.TeamRows()); err != nil {
+			return err
+		}
+	}
+	if *playersOut != "" {
+		if err := writeCSVFile(*playersOut, summary.PlayerHeaders, s.PlayerRows()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
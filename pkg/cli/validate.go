@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/validate"
+)
+
+var validateCommand = &Command{
+	Name:    "validate",
+	Summary: "Check the integrity of a downloaded events archive",
+	Run:     runValidate,
+}
+
+// runValidate validates an events archive and prints the report. It fails when issues are
+// found, so that the exit code can be used by scripts.
+func runValidate(args []string) error {
+	fs := newFlagSet("validate")
+	seriesID := fs.String("series", "", "ID of a series whose events archive is in the download library")
+	file := fs.String("file", "", "path of an events archive")
+	format := fs.String("format", "text", "report format: 'text' or 'json'")
+	maxIssues := fs.Int("max-issues", 100, "maximum number of issues listed in the report, 0 for all")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *format != "text" && *format != "json" {
+		return usageError(fmt.Sprintf("unknown format %q", *format))
+	}
+
+	path, err := archivePath(*seriesID, *file)
+	if err != nil {
+		return err
+	}
+	report, err := validate.ValidateArchive(path, validate.Options{MaxIssues: *maxIssues})
+	if err != nil {
+		return err
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printReport(report)
+	}
+
+	if !report.Valid {
+		total := 0
+		for _, count := range report.Counts {
+			total += count
+		}
+		return fmt.Errorf("%d issues found in %s", total, path)
+	}
+	return nil
+}
+
+// printReport writes a validation report in a human readable form.
+func printReport(report *validate.Report) {
+	fmt.Printf("Series %s: %d transactions, %d events, %d games\n", report.SeriesID, report.Transactions, report.Events, report.Games)
+	if report.Valid {
+		fmt.Println("No issues found.")
+		return
+	}
+
+	checks := make([]string, 0, len(report.Counts))
+	for check := range report.Counts {
+		checks = append(checks, check)
+	}
+	sort.Strings(checks)
+	for _, check := range checks {
+		fmt.Printf("%-16s %d\n", check, report.Counts[check])
+	}
+	fmt.Println()
+	for _, issue := range report.Issues {
+		fmt.Printf("[%s] seq %d: %s\n", issue.Check, issue.SequenceNumber, issue.Message)
+	}
+	if report.Truncated {
+		fmt.Println("...")
+	}
+}
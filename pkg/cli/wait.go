@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/config"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/convert"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/graphql"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/library"
+)
+
+var waitCommand = &Command{
+	Name:    "wait",
+	Summary: "Wait until the files of a series are available, then download them",
+	Run:     runWait,
+}
+
+// runWait polls the file list of a series until the requested files are available and
+// downloads them, skipping files already recorded in the library unless forced.
+func runWait(args []string) error {
+	fs := newFlagSet("wait")
+	seriesID := fs.String("series", "", "ID of the series to wait for (required)")
+	files := fs.String("files", "json", "comma separated files to wait for: 'json' for the events file and game numbers for replays")
+	directory := fs.String("dir", "", "directory where the files are saved (default: the download directory of the profile, or the current directory)")
+	timeout := fs.Duration("timeout", 6*time.Hour, "maximum time to wait for the files")
+	interval := fs.Duration("interval", graphql.DefaultBackoff.Initial, "initial delay between two checks")
+	maxInterval := fs.Duration("max-interval", graphql.DefaultBackoff.Max, "maximum delay between two checks")
+	force := fs.Bool("force", false, "download files that are already in the library again")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *seriesID == "" {
+		return usageError("the -series flag is required")
+	}
+	if *directory == "" {
+		*directory = config.GetDownloadDir()
+	}
+	if *directory == "" {
+		*directory = "."
+	}
+
+	fileIDs := fileIDsFromFlag(*files)
+	if len(fileIDs) == 0 {
+		return usageError("the -files flag must list at least one file")
+	}
+
+	if err := config.RequireAPIKey(); err != nil {
+		return err
+	}
+	lib, err := library.Open()
+	if err != nil {
+		return err
+	}
+
+	// Files already in the library are not waited for, so that waiting for a series that is
+	// already downloaded returns at once.
+	missing := fileIDs
+	if !*force {
+		missing = nil
+		for _, fileID := range fileIDs {
+			if entry, ok := lib.Find(*seriesID, fileID); ok {
+				fmt.Fprintf(os.Stderr, "skipping %s, already downloaded to %s\n", fileID, entry.Path)
+				continue
+			}
+			missing = append(missing, fileID)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	// The metadata of the series is recorded with its files, for the commands reading the library.
+	series, err := graphql.FetchSeries(*seriesID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	backoff := graphql.Backoff{Initial: *interval, Max: *maxInterval, Factor: graphql.DefaultBackoff.Factor}
+	err = graphql.WaitForFiles(ctx, *seriesID, missing, backoff, func(p graphql.WaitProgress) {
+		if p.Err != nil {
+			fmt.Fprintf(os.Stderr, "check %d: %v; next check in %s\n", p.Attempt, p.Err, p.Next)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "check %d: %d replays, events file available: %v; next check in %s\n", p.Attempt, p.RoflCount, p.HasJSON, p.Next)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, fileID := range missing {
+		path, err := graphql.DownloadFile(*seriesID, fileID, *directory)
+		if err != nil {
+			return fmt.Errorf("error downloading %s: %v", fileID, err)
+		}
+		entry, err := lib.Record(librarySeries(series), fileID, path)
+		if err != nil {
+			return err
+		}
+		if err := convert.PostDownload(entry); err != nil {
+			return err
+		}
+		fmt.Println(path)
+	}
+	return nil
+}
+
+// librarySeries converts the metadata of a series returned by the API to the metadata
+// recorded in the library.
+func librarySeries(s graphql.Series) library.Series {
+	return library.Series{
+		ID:         s.ID,
+		TitleID:    s.Title.ID,
+		StartTime:  s.StartTimeScheduled,
+		Tournament: s.Tournament.Name,
+		Teams:      s.TeamNames(),
+	}
+}
+
+// fileIDsFromFlag converts the -files flag value to download option identifiers.
+func fileIDsFromFlag(value string) []string {
+	var fileIDs []string
+	for _, item := range splitList(value) {
+		if item == "json" {
+			item = graphql.EventsFileID
+		}
+		fileIDs = append(fileIDs, item)
+	}
+	return fileIDs
+}
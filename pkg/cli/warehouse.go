@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/config"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/graphql"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/warehouse"
+)
+
+var ingestCommand = &Command{
+	Name:    "ingest",
+	Summary: "Load series metadata and downloaded events into the SQLite warehouse",
+	Run:     runIngest,
+}
+
+var sqlCommand = &Command{
+	Name:    "sql",
+	Summary: "Run a SQL query on the SQLite warehouse",
+	Run:     runSQL,
+}
+
+// openWarehouse opens the warehouse at the given path, or at the default path if it is empty.
+func openWarehouse(path string) (*warehouse.Warehouse, error) {
+	if path == "" {
+		var err error
+		if path, err = warehouse.DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+	return warehouse.Open(path)
+}
+
+// runIngest loads series metadata from the GRID API and events archives from the library
+// or from a file into the warehouse.
+func runIngest(args []string) error {
+	fs := newFlagSet("ingest")
+	db := fs.String("db", "", "path of the warehouse database (default: warehouse.db in the configuration directory)")
+	seriesID := fs.String("series", "", "ingest the events archive of this series from the download library")
+	file := fs.String("file", "", "ingest the events archive at this path")
+	all := fs.Bool("all", false, "ingest every events archive of the download library")
+	titleID := fs.String("title", "", "ingest the metadata of the series of this title ID from the GRID API")
+	pastDays := fs.Int("past-days", 7, "number of past days of series metadata to ingest with -title")
+	futureDays := fs.Int("future-days", 1, "number of future days of series metadata to ingest with -title")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *titleID == "" && *seriesID == "" && *file == "" && !*all {
+		return usageError("one of the -title, -series, -file or -all flags is required")
+	}
+	if *titleID != "" {
+		if err := config.RequireAPIKey(); err != nil {
+			return err
+		}
+	}
+
+	w, err := openWarehouse(*db)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	if *titleID != "" {
+		startTime := time.Now().Add(time.Duration(-*pastDays) * 24 * time.Hour)
+		endTime := time.Now().Add(time.Duration(*futureDays) * 24 * time.Hour)
+		series, err := graphql.FetchAllSeries(*titleID, startTime, endTime)
+		if err != nil {
+			return err
+		}
+		if err := w.IngestSeries(*titleID, series); err != nil {
+			return err
+		}
+		fmt.Printf("%d series ingested\n", len(series))
+	}
+
+	if *seriesID == "" && *file == "" && !*all {
+		return nil
+	}
+	entries, err := libraryArchives(*seriesID, *file, *all)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		count, err := w.IngestArchive(e.Path, e.Series)
+		if err != nil {
+			return fmt.Errorf("%s: %v", e.Path, err)
+		}
+		fmt.Printf("%s: %d events ingested\n", e.Path, count)
+	}
+	return nil
+}
+
+// runSQL runs a query on the warehouse and prints or exports its result.
+func runSQL(args []string) error {
+	fs := newFlagSet("sql")
+	db := fs.String("db", "", "path of the warehouse database (default: warehouse.db in the configuration directory)")
+	format := fs.String("format", "table", "output format: 'table', 'csv' or 'json'")
+	out := fs.String("out", "", "write the result to this file instead of the standard output")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *format != "table" && *format != "csv" && *format != "json" {
+		return usageError(fmt.Sprintf("unknown format %q", *format))
+	}
+
+	query := strings.Join(fs.Args(), " ")
+	if query == "" || query == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("error reading query: %v", err)
+		}
+		query = string(data)
+	}
+	if strings.TrimSpace(query) == "" {
+		return usageError("a query is required")
+	}
+
+	w, err := openWarehouse(*db)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	columns, rows, err := w.Query(query)
+	if err != nil {
+		return err
+	}
+
+	var output io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("error creating file: %v", err)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	switch *format {
+	case "table":
+		return printTable(output, columns, rows)
+	case "csv":
+		writer := csv.NewWriter(output)
+		if err := writer.Write(columns); err != nil {
+			return err
+		}
+		return writer.WriteAll(rows)
+	case "json":
+		records := make([]map[string]string, len(rows))
+		for i, row := range rows {
+			records[i] = make(map[string]string, len(columns))
+			for j, column := range columns {
+				records[i][column] = row[j]
+			}
+		}
+		enc := json.NewEncoder(output)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	}
+	return nil
+}
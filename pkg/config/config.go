@@ -1,7 +1,10 @@
 // Package config provides functionality for managing the configuration of the Stealth Grid CLI application.
 //
 // This package handles reading, writing, and initializing configuration files,
-// including retrieving the API key necessary for accessing the Grid API.
+// including retrieving the API key necessary for accessing the Grid API. Settings given as
+// options, e.g. from command line flags, take precedence over the active profile, then over
+// the GRID_API_KEY, GRID_API_URL and CONFIG_PATH environment variables, then over the top
+// level of the configuration file.
 package config
 
 import (
@@ -12,19 +15,22 @@ import (
 	"strings"
 
 	"github.com/spf13/viper"
+	"golang.org/x/term"
 )
 
+// APIURL is the base URL of the GRID API. It is replaced by LoadConfig when the URL is
+// configured with the api_url setting, the GRID_API_URL environment variable or a flag.
 var APIURL = "https://api.grid.gg"
 
-// getConfigPath returns the path to the configuration file.
+// Dir returns the directory holding the application's configuration files.
 //
-// It creates the necessary directories if they do not exist.
+// It creates the directory if it does not exist.
 //
 // Returns:
-//   - string: The path to the configuration file.
+//   - string: The path to the configuration directory.
 //   - error: An error if there is any issue determining the user's home directory
 //     or creating the configuration directory.
-func getConfigPath() (string, error) {
+func Dir() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
@@ -36,54 +42,251 @@ func getConfigPath() (string, error) {
 			return "", err
 		}
 	}
-	return filepath.Join(configDir, "config.yaml"), nil
+	return configDir, nil
+}
+
+// Environment variables overriding the configuration file.
+const (
+	// EnvConfigPath is the environment variable holding the path of the configuration file.
+	EnvConfigPath = "CONFIG_PATH"
+
+	// EnvAPIKey is the environment variable holding the GRID API key.
+	EnvAPIKey = "GRID_API_KEY"
+
+	// EnvAPIURL is the environment variable holding the base URL of the GRID API.
+	EnvAPIURL = "GRID_API_URL"
+
+	// EnvProfile is the environment variable holding the name of the profile to use.
+	EnvProfile = "GRID_PROFILE"
+)
+
+// Options overrides the configuration, typically with command line flags. Empty fields are
+// not overridden.
+type Options struct {
+	Path    string // Path is the path of the configuration file.
+	APIKey  string // APIKey is the GRID API key.
+	APIURL  string // APIURL is the base URL of the GRID API, e.g. "https://api.grid.gg".
+	Profile string // Profile is the name of the profile to use.
 }
 
-// InitConfig initializes the configuration by reading from or creating a config file.
+// overrides are the options the configuration was initialized with.
+var overrides Options
+
+// getConfigPath returns the path to the configuration file.
 //
-// If the configuration file does not exist or is incomplete, it prompts the user to enter the API key
-// and saves it to the configuration file.
+// The path given in the options takes precedence over the CONFIG_PATH environment variable,
+// which takes precedence over config.yaml in the configuration directory. The necessary
+// directories are created if they do not exist.
+//
+// Parameters:
+//   - opts: The options overriding the path of the configuration file.
+//
+// Returns:
+//   - string: The path to the configuration file.
+//   - error: An error if there is any issue determining the user's home directory
+//     or creating the configuration directory.
+func getConfigPath(opts Options) (string, error) {
+	if opts.Path != "" {
+		return opts.Path, nil
+	}
+	if path := strings.TrimSpace(os.Getenv(EnvConfigPath)); path != "" {
+		return path, nil
+	}
+	configDir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "config.yaml"), nil
+}
+
+// InitConfig initializes the configuration from the environment and the configuration file,
+// without overrides. See InitConfigWith.
 //
 // Returns:
 //   - error: An error if there is any issue reading or writing the configuration file, or if the API key is not set up correctly.
 func InitConfig() error {
-	configPath, err := getConfigPath()
+	return InitConfigWith(Options{})
+}
+
+// InitConfigWith loads the configuration and makes sure the API key is set up, as needed
+// before calling the GRID API. See LoadConfig and RequireAPIKey.
+//
+// Parameters:
+//   - opts: The settings overriding the environment and the configuration file.
+//
+// Returns:
+//   - error: An error if there is any issue reading or writing the configuration file, or if the API key is not set up correctly.
+func InitConfigWith(opts Options) error {
+	if err := LoadConfig(opts); err != nil {
+		return err
+	}
+	return RequireAPIKey()
+}
+
+// LoadConfig loads the configuration file, if it exists, without requiring the API key, so
+// that commands working offline run without one.
+//
+// Each setting is taken from the options, then from the active profile, then from the
+// environment (GRID_API_KEY and GRID_API_URL), then from the top level of the configuration
+// file. The profile is chosen with the options, the GRID_PROFILE environment variable or the
+// "profile" key of the configuration file.
+//
+// Parameters:
+//   - opts: The settings overriding the environment and the configuration file.
+//
+// Returns:
+//   - error: An error if the configuration file cannot be read or if the profile is unknown.
+func LoadConfig(opts Options) error {
+	configPath, err := getConfigPath(opts)
 	if err != nil {
 		return fmt.Errorf("error getting configuration file path: %v", err)
 	}
 
 	viper.SetConfigFile(configPath)
 	viper.SetConfigType("yaml")
+	overrides = opts
+	if baseAPIURL == "" {
+		baseAPIURL = APIURL
+	}
 
-	if err := viper.ReadInConfig(); err != nil {
-		fmt.Println("Configuration not found. Please set up the API key:")
-		reader := bufio.NewReader(os.Stdin)
-		fmt.Print("Enter the API key: ")
-		apiKey, _ := reader.ReadString('\n')
-		apiKey = strings.TrimSpace(apiKey)
-
-		viper.Set("api_key", apiKey)
-		err = viper.WriteConfigAs(configPath)
-		if err != nil {
-			return fmt.Errorf("error saving configuration: %v", err)
-		}
-		fmt.Println("Configuration saved successfully.")
-	} else {
-		apiKey := viper.GetString("api_key")
-		if apiKey == "" {
-			return fmt.Errorf("API key is not set up correctly. Please set up the API key")
+	if _, err := os.Stat(configPath); err == nil {
+		if err := viper.ReadInConfig(); err != nil {
+			return fmt.Errorf("error reading configuration %s: %v", configPath, err)
 		}
 	}
 
-	return nil
+	name := opts.Profile
+	if name == "" {
+		name = os.Getenv(EnvProfile)
+	}
+	if name == "" {
+		name = viper.GetString("profile")
+	}
+	return selectProfile(name)
 }
 
-// GetAPIKey retrieves the API key from the configuration file.
+// RequireAPIKey makes sure an API key is configured, before calling the GRID API.
 //
-// It reads the API key from the configuration file managed by Viper and trims any leading or trailing whitespace.
+// If no API key is found anywhere, no configuration file exists yet and the standard input
+// is a terminal, the user is prompted for it and it is saved to the configuration file.
+// Otherwise, e.g. in CI jobs and containers, an error is returned instead of waiting for input.
 //
 // Returns:
-//   - string: The API key.
+//   - error: An error if the API key is not set up correctly or cannot be saved.
+func RequireAPIKey() error {
+	if GetAPIKey() != "" {
+		return nil
+	}
+	configPath := viper.ConfigFileUsed()
+	_, statErr := os.Stat(configPath)
+	if statErr == nil || !isTerminal(os.Stdin) {
+		return fmt.Errorf("API key is not set up correctly. Set the %s environment variable, pass --api-key or add api_key to %s", EnvAPIKey, configPath)
+	}
+	fmt.Fprintln(os.Stderr, "Configuration not found. Please set up the API key:")
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Fprint(os.Stderr, "Enter the API key: ")
+	apiKey, _ := reader.ReadString('\n')
+	apiKey = strings.TrimSpace(apiKey)
+	if apiKey == "" {
+		return fmt.Errorf("API key is not set up correctly. Please set up the API key")
+	}
+
+	viper.Set("api_key", apiKey)
+	if err := viper.WriteConfigAs(configPath); err != nil {
+		return fmt.Errorf("error saving configuration: %v", err)
+	}
+	fmt.Fprintln(os.Stderr, "Configuration saved successfully.")
+	return nil
+}
+
+// isTerminal reports whether a file is an interactive terminal. Other character devices, such
+// as /dev/null, are not.
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// GetAPIKey retrieves the API key from the options, the active profile, the environment or
+// the configuration file.
+//
+// Returns:
+//   - string: The API key, without leading or trailing whitespace.
 func GetAPIKey() string {
-	return strings.TrimSpace(viper.GetString("api_key"))
+	return setting("api_key", EnvAPIKey, overrides.APIKey)
+}
+
+// GetPostDownloadParquetDir retrieves the directory where downloaded events archives are converted to Parquet.
+//
+// It reads the "post_download.parquet_dir" setting from the configuration file managed by Viper.
+//
+// Returns:
+//   - string: The directory of the Parquet dataset, or an empty string if the conversion is disabled.
+func GetPostDownloadParquetDir() string {
+	return strings.TrimSpace(viper.GetString("post_download.parquet_dir"))
+}
+
+// GetRedactDropFields retrieves the fields removed from events archives by the redact command.
+//
+// It reads the "redact.drop_fields" list from the configuration file managed by Viper.
+//
+// Returns:
+//   - []string: The names of the fields to remove, or nil if none are configured.
+func GetRedactDropFields() []string {
+	return viper.GetStringSlice("redact.drop_fields")
+}
+
+// GetReportTemplate retrieves the path of the custom template of schedule reports of a format.
+//
+// It reads the "report.<name>_template" key from the configuration file managed by Viper,
+// e.g. "report.markdown_template" or "report.html_template".
+//
+// Parameters:
+//   - name: The name of the report format, "markdown" or "html".
+//
+// Returns:
+//   - string: The path of the template, or an empty string to use the default template.
+func GetReportTemplate(name string) string {
+	return strings.TrimSpace(viper.GetString("report." + name + "_template"))
+}
+
+// GetSeriesColumns retrieves the ordered columns of the series table and of its exports.
+//
+// It reads the "series.columns" list from the configuration file managed by Viper, e.g.
+// [start_time, series_id, format, team_one, team_two].
+//
+// Returns:
+//   - []string: The keys of the columns, or nil to use the default columns.
+func GetSeriesColumns() []string {
+	return viper.GetStringSlice("series.columns")
+}
+
+// SetSeriesColumns saves the ordered columns of the series table to the configuration file.
+//
+// Parameters:
+//   - columns: The keys of the columns.
+//
+// Returns:
+//   - error: An error if the configuration file cannot be written.
+func SetSeriesColumns(columns []string) error {
+	return saveSetting("series.columns", columns)
+}
+
+// saveSetting sets a key of the configuration and saves it to the configuration file.
+//
+// Only the content of the file is written back, so that settings given with options or
+// environment variables, such as the API key, are not saved.
+func saveSetting(key string, value any) error {
+	viper.Set(key, value)
+	file := viper.New()
+	file.SetConfigFile(viper.ConfigFileUsed())
+	file.SetConfigType("yaml")
+	if _, err := os.Stat(viper.ConfigFileUsed()); err == nil {
+		if err := file.ReadInConfig(); err != nil {
+			return fmt.Errorf("error reading config: %v", err)
+		}
+	}
+	file.Set(key, value)
+	if err := file.WriteConfigAs(viper.ConfigFileUsed()); err != nil {
+		return fmt.Errorf("error saving config: %v", err)
+	}
+	return nil
 }
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestInitConfigWith(t *testing.T) {
+	defer func(url string) { APIURL = url }(APIURL)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("api_key: file-key\napi_url: https://file.example\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	viper.Reset()
+	t.Setenv(EnvConfigPath, path)
+	t.Setenv(EnvAPIKey, "")
+	t.Setenv(EnvAPIURL, "")
+	if err := InitConfig(); err != nil {
+		t.Fatalf("Failed to initialize config: %v", err)
+	}
+	if GetAPIKey() != "file-key" || APIURL != "https://file.example" {
+		t.Fatalf("Expected the settings of %s, but got %q and %q", EnvConfigPath, GetAPIKey(), APIURL)
+	}
+
+	viper.Reset()
+	t.Setenv(EnvAPIKey, "env-key")
+	t.Setenv(EnvAPIURL, "https://env.example/")
+	if err := InitConfig(); err != nil {
+		t.Fatalf("Failed to initialize config: %v", err)
+	}
+	if GetAPIKey() != "env-key" || APIURL != "https://env.example" {
+		t.Fatalf("Expected the environment to override the file, but got %q and %q", GetAPIKey(), APIURL)
+	}
+
+	viper.Reset()
+	if err := InitConfigWith(Options{APIKey: "flag-key"}); err != nil {
+		t.Fatalf("Failed to initialize config: %v", err)
+	}
+	if GetAPIKey() != "flag-key" {
+		t.Fatalf("Expected the options to override the environment, but got %q", GetAPIKey())
+	}
+
+	// Without a terminal, a missing API key is an error rather than a prompt, but only for
+	// the API: loading the configuration alone succeeds.
+	viper.Reset()
+	t.Setenv(EnvAPIKey, "")
+	if err := LoadConfig(Options{Path: filepath.Join(dir, "missing.yaml")}); err != nil {
+		t.Fatalf("Expected the configuration to load without an API key, but got %v", err)
+	}
+	if err := InitConfigWith(Options{Path: filepath.Join(dir, "missing.yaml")}); err == nil {
+		t.Fatalf("Expected an error for a missing API key")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "missing.yaml")); !os.IsNotExist(err) {
+		t.Fatalf("Expected no configuration file to be created, but got %v", err)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	defer func(url string) { APIURL = url }(APIURL)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `api_key: prod-key
+default_title: "3"
+profile: scrims
+profiles:
+  scrims:
+    api_key: scrim-key
+    download_dir: /data/scrims
+  staging:
+    api_key: staging-key
+    api_url: https://staging.example/
+    default_title: "6"
+  keyless:
+    api_url: https://keyless.example
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	viper.Reset()
+	t.Setenv(EnvAPIKey, "")
+	t.Setenv(EnvAPIURL, "")
+	t.Setenv(EnvProfile, "")
+	if err := InitConfigWith(Options{Path: path}); err != nil {
+		t.Fatalf("Failed to initialize config: %v", err)
+	}
+	if ActiveProfile() != "scrims" || GetAPIKey() != "scrim-key" || GetDownloadDir() != "/data/scrims" || GetDefaultTitle() != "3" {
+		t.Fatalf("Expected the profile of the file, but got %q: %q %q %q", ActiveProfile(), GetAPIKey(), GetDownloadDir(), GetDefaultTitle())
+	}
+	scrimsURL := APIURL
+
+	// The profile takes precedence over the environment, so that the exported key is not sent
+	// to the API of the profile.
+	t.Setenv(EnvProfile, "staging")
+	t.Setenv(EnvAPIKey, "env-key")
+	if err := InitConfigWith(Options{Path: path}); err != nil {
+		t.Fatalf("Failed to initialize config: %v", err)
+	}
+	if GetAPIKey() != "staging-key" || APIURL != "https://staging.example" || GetDefaultTitle() != "6" {
+		t.Fatalf("Expected the staging profile, but got %q %q %q", GetAPIKey(), APIURL, GetDefaultTitle())
+	}
+
+	if err := UseProfile(""); err != nil || APIURL != scrimsURL || GetDownloadDir() != "" || GetAPIKey() != "env-key" {
+		t.Fatalf("Expected the top-level settings, but got %q %q %q (%v)", APIURL, GetDownloadDir(), GetAPIKey(), err)
+	}
+	if err := UseProfile("missing"); err == nil {
+		t.Fatalf("Expected an error for an unknown profile")
+	}
+	if err := UseProfile("keyless"); err == nil || ActiveProfile() != "" {
+		t.Fatalf("Expected an error for a profile with an API URL but no API key, but got %v", err)
+	}
+	if err := InitConfigWith(Options{Path: path, Profile: "Missing"}); err == nil {
+		t.Fatalf("Expected an error for an unknown profile flag")
+	}
+	if names := Profiles(); len(names) != 3 || names[0].Name != "keyless" || names[2].APIURL != "https://staging.example/" {
+		t.Fatalf("Unexpected profiles %+v", names)
+	}
+
+	// Without a top-level key, switching back to the top-level settings is refused.
+	t.Setenv(EnvAPIKey, "")
+	viper.Set("api_key", "")
+	if err := UseProfile("scrims"); err != nil {
+		t.Fatalf("Failed to use profile: %v", err)
+	}
+	if err := UseProfile(""); err == nil || ActiveProfile() != "scrims" {
+		t.Fatalf("Expected an error for the top-level settings without an API key, but got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+var (
+	// profile is the name of the active profile, empty when the top level of the configuration file is used.
+	profile string
+
+	// baseAPIURL is the API URL used when none is configured, the value of APIURL when the
+	// configuration was first initialized.
+	baseAPIURL string
+)
+
+// Profile holds the settings of a named profile of the configuration file.
+//
+// Profiles are listed under the "profiles" key, e.g. to use different API keys for production
+// data, scrim data and a staging endpoint:
+//
+//	profiles:
+//	  scrims:
+//	    api_key: ...
+//	    default_title: "3"
+//	    download_dir: /data/scrims
+//	  staging:
+//	    api_key: ...
+//	    api_url: https://staging.example
+//
+// The settings of the active profile take precedence over the environment, so that an
+// exported GRID_API_KEY is not sent to the API of another profile. A profile setting an API
+// URL must also set its API key, unless one is given as an option.
+type Profile struct {
+	Name         string // Name is the name of the profile, in lower case.
+	APIURL       string // APIURL is the base URL of the GRID API of the profile, if set.
+	DefaultTitle string // DefaultTitle is the title ID selected by default, if set.
+	DownloadDir  string // DownloadDir is the directory downloads are saved to, if set.
+}
+
+// Profiles returns the profiles of the configuration file, sorted by name.
+func Profiles() []Profile {
+	var profiles []Profile
+	for name := range viper.GetStringMap("profiles") {
+		key := "profiles." + name + "."
+		profiles = append(profiles, Profile{
+			Name:         name,
+			APIURL:       strings.TrimSpace(viper.GetString(key + "api_url")),
+			DefaultTitle: strings.TrimSpace(viper.GetString(key + "default_title")),
+			DownloadDir:  strings.TrimSpace(viper.GetString(key + "download_dir")),
+		})
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles
+}
+
+// ActiveProfile returns the name of the active profile, or an empty string if no profile is used.
+func ActiveProfile() string {
+	return profile
+}
+
+// UseProfile activates a profile, so that its settings take precedence over the top level of
+// the configuration file, and points the API client at the API URL of the profile.
+//
+// Parameters:
+//   - name: The name of the profile, ignoring case, or an empty string to use no profile.
+//
+// Returns:
+//   - error: An error if the configuration file has no such profile, or if the profile
+//     leaves no API key configured.
+func UseProfile(name string) error {
+	previous := profile
+	if err := selectProfile(name); err != nil {
+		return err
+	}
+	if GetAPIKey() == "" {
+		selectProfile(previous)
+		if name == "" {
+			return fmt.Errorf("the top-level settings have no API key")
+		}
+		return fmt.Errorf("profile %q has no API key", strings.ToLower(strings.TrimSpace(name)))
+	}
+	return nil
+}
+
+// selectProfile activates a profile like UseProfile, without requiring an API key.
+func selectProfile(name string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name != "" && !viper.IsSet("profiles."+name) {
+		var names []string
+		for _, p := range Profiles() {
+			names = append(names, p.Name)
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("unknown profile %q; no profiles are configured in %s", name, viper.ConfigFileUsed())
+		}
+		return fmt.Errorf("unknown profile %q; available profiles are %s", name, strings.Join(names, ", "))
+	}
+	if name != "" && strings.TrimSpace(overrides.APIKey) == "" {
+		key := "profiles." + name + "."
+		if strings.TrimSpace(viper.GetString(key+"api_url")) != "" && strings.TrimSpace(viper.GetString(key+"api_key")) == "" {
+			return fmt.Errorf("profile %q sets api_url but no api_key; add the API key of %s to the profile", name, viper.GetString(key+"api_url"))
+		}
+	}
+	profile = name
+
+	APIURL = baseAPIURL
+	if url := strings.TrimRight(setting("api_url", EnvAPIURL, overrides.APIURL), "/"); url != "" {
+		APIURL = url
+	}
+	return nil
+}
+
+// GetDefaultTitle retrieves the title ID selected by default, from the active profile or the
+// "default_title" key of the configuration file.
+//
+// Returns:
+//   - string: The title ID, or an empty string if none is configured.
+func GetDefaultTitle() string {
+	return setting("default_title", "", "")
+}
+
+// GetDownloadDir retrieves the directory downloads are saved to without asking, from the
+// active profile or the "download_dir" key of the configuration file.
+//
+// Returns:
+//   - string: The directory, or an empty string to choose it for each download.
+func GetDownloadDir() string {
+	return setting("download_dir", "", "")
+}
+
+// setting resolves a setting from, in order of precedence, an option, the active profile, an
+// environment variable and the top level of the configuration file.
+//
+// Parameters:
+//   - key: The key of the setting in the configuration file and in profiles.
+//   - env: The environment variable of the setting, or an empty string if it has none.
+//   - override: The value given as an option, or an empty string.
+//
+// Returns:
+//   - string: The value of the setting without surrounding whitespace, empty if it is not set.
+func setting(key, env, override string) string {
+	if v := strings.TrimSpace(override); v != "" {
+		return v
+	}
+	if profile != "" {
+		if v := strings.TrimSpace(viper.GetString("profiles." + profile + "." + key)); v != "" {
+			return v
+		}
+	}
+	if env != "" {
+		if v := strings.TrimSpace(os.Getenv(env)); v != "" {
+			return v
+		}
+	}
+	return strings.TrimSpace(viper.GetString(key))
+}
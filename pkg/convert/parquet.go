@@ -0,0 +1,214 @@
+// Package convert converts downloaded events archives to formats used by analytics tools.
+//
+// Events are flattened into a wide Parquet table, described by EventRow, with one row per
+// event. The files are partitioned by title, tournament and series using Hive-style
+// directories, e.g. "title=3/tournament=LCK/series=2620066/events.parquet", so that they
+// can be read as a single dataset by Spark, DuckDB, Polars or pandas.
+package convert
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress/zstd"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/config"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/graphql"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/library"
+)
+
+// batchSize is the number of rows buffered before they are written to the Parquet file.
+const batchSize = 1024
+
+// EventRow is the schema of the Parquet files, with one row per event.
+//
+// State deltas are stored as JSON strings, as their structure depends on the title and the
+// event type. Full states are not stored.
+type EventRow struct {
+	TitleID          string    `parquet:"title_id"`                           // TitleID is the GRID title ID, if known.
+	Tournament       string    `parquet:"tournament"`                         // Tournament is the tournament name, if known.
+	SeriesID         string    `parquet:"series_id"`                          // SeriesID is the GRID series ID.
+	GameID           string    `parquet:"game_id"`                            // GameID is the ID of the game in progress, empty between games.
+	GameNumber       int32     `parquet:"game_number"`                        // GameNumber is the number of the game in progress, 0 between games.
+	TransactionID    string    `parquet:"transaction_id"`                     // TransactionID is the ID of the transaction.
+	SequenceNumber   int64     `parquet:"sequence_number"`                    // SequenceNumber is the sequence number of the transaction.
+	EventIndex       int32     `parquet:"event_index"`                        // EventIndex is the position of the event in its transaction.
+	OccurredAt       time.Time `parquet:"occurred_at,timestamp(millisecond)"` // OccurredAt is the time the event occurred, in UTC.
+	EventID          string    `parquet:"event_id"`                           // EventID is the ID of the event.
+	EventType        string    `parquet:"event_type,dict"`                    // EventType is the event type, e.g. "player-killed-player".
+	ActorType        string    `parquet:"actor_type,dict"`                    // ActorType is the type of the actor.
+	ActorID          string    `parquet:"actor_id"`                           // ActorID is the ID of the actor.
+	Action           string    `parquet:"action,dict"`                        // Action is the action of the event.
+	TargetType       string    `parquet:"target_type,dict"`                   // TargetType is the type of the target.
+	TargetID         string    `parquet:"target_id"`                          // TargetID is the ID of the target.
+	ActorStateDelta  string    `parquet:"actor_state_delta,optional"`         // ActorStateDelta is the change of the actor state, as JSON.
+	TargetStateDelta string    `parquet:"target_state_delta,optional"`        // TargetStateDelta is the change of the target state, as JSON.
+	SeriesStateDelta string    `parquet:"series_state_delta,optional"`        // SeriesStateDelta is the change of the series state, as JSON.
+}
+
+// Partition identifies the partition a series is written to.
+type Partition struct {
+	TitleID    string // TitleID is the GRID title ID.
+	Tournament string // Tournament is the tournament name.
+	SeriesID   string // SeriesID is the GRID series ID.
+}
+
+// PartitionFor returns the partition of a series recorded in the library.
+func PartitionFor(series library.Series) Partition {
+	return Partition{TitleID: series.TitleID, Tournament: series.Tournament, SeriesID: series.ID}
+}
+
+// Dir returns the directory of the partition inside a dataset directory.
+//
+// Unknown values are written as "unknown" and values are escaped to be valid path segments.
+func (p Partition) Dir(root string) string {
+	segment := func(key, value string) string {
+		if value == "" {
+			value = "unknown"
+		}
+		return key + "=" + url.PathEscape(value)
+	}
+	return filepath.Join(root, segment("title", p.TitleID), segment("tournament", p.Tournament), segment("series", p.SeriesID))
+}
+
+// ArchiveToParquet converts an events archive to a Parquet file inside a dataset directory.
+//
+// Parameters:
+//   - path: The path of the events archive downloaded with graphql.DownloadJSON.
+//   - root: The root directory of the dataset.
+//   - p: The partition of the series. An empty series ID is replaced by the one found in the events,
+//     or by the name of the archive if the events have none.
+//
+// Returns:
+//   - string: The path of the written Parquet file.
+//   - int: The number of rows written.
+//   - error: An error if the archive cannot be read or the Parquet file cannot be written.
+func ArchiveToParquet(path, root string, p Partition) (string, int, error) {
+	archive, err := events.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer archive.Close()
+
+	// The series ID is taken from the events rather than from the file name, which may have
+	// been renamed, e.g. to 2620066.redacted.zip.
+	if p.SeriesID == "" {
+		for env, err := range archive.Events() {
+			if err == nil {
+				p.SeriesID = env.SeriesID
+			}
+			break
+		}
+	}
+	if p.SeriesID == "" {
+		p.SeriesID = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	dir := p.Dir(root)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", 0, fmt.Errorf("error creating partition directory: %v", err)
+	}
+
+	outPath := filepath.Join(dir, "events.parquet")
+	tmpPath := outPath + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return "", 0, fmt.Errorf("error creating Parquet file: %v", err)
+	}
+	defer os.Remove(tmpPath)
+	defer file.Close()
+
+	writer := parquet.NewGenericWriter[EventRow](file, parquet.Compression(&zstd.Codec{}))
+	batch := make([]EventRow, 0, batchSize)
+	flush := func() error {
+		if _, err := writer.Write(batch); err != nil {
+			return fmt.Errorf("error writing Parquet rows: %v", err)
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	var gameID string
+	var gameNumber int32
+	count := 0
+	for env, err := range archive.Events() {
+		if err != nil {
+			return "", 0, err
+		}
+		if env.Type == "series-started-game" {
+			gameID = env.Target.ID
+			gameNumber++
+		}
+
+		batch = append(batch, EventRow{
+			TitleID:          p.TitleID,
+			Tournament:       p.Tournament,
+			SeriesID:         p.SeriesID,
+			GameID:           gameID,
+			GameNumber:       gameNumber,
+			TransactionID:    env.TransactionID,
+			SequenceNumber:   env.SequenceNumber,
+			EventIndex:       int32(env.Index),
+			OccurredAt:       env.OccurredAt.UTC(),
+			EventID:          env.ID,
+			EventType:        env.Type,
+			ActorType:        env.Actor.Type,
+			ActorID:          env.Actor.ID,
+			Action:           env.Action,
+			TargetType:       env.Target.Type,
+			TargetID:         env.Target.ID,
+			ActorStateDelta:  string(env.Actor.StateDelta),
+			TargetStateDelta: string(env.Target.StateDelta),
+			SeriesStateDelta: string(env.SeriesStateDelta),
+		})
+		count++
+
+		if env.Type == "series-ended-game" {
+			gameID = ""
+		}
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return "", 0, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return "", 0, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", 0, fmt.Errorf("error closing Parquet writer: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", 0, fmt.Errorf("error closing Parquet file: %v", err)
+	}
+	if err := os.Rename(tmpPath, outPath); err != nil {
+		return "", 0, fmt.Errorf("error saving Parquet file: %v", err)
+	}
+	return outPath, count, nil
+}
+
+// PostDownload runs the conversions configured to happen after a download.
+//
+// When "post_download.parquet_dir" is set in the configuration, downloaded events archives
+// are converted to Parquet into that directory. Other files are ignored.
+//
+// Parameters:
+//   - entry: The library entry of the downloaded file.
+//
+// Returns:
+//   - error: An error if a configured conversion fails.
+func PostDownload(entry library.Entry) error {
+	root := config.GetPostDownloadParquetDir()
+	if root == "" || entry.FileID != graphql.EventsFileID {
+		return nil
+	}
+	if _, _, err := ArchiveToParquet(entry.Path, root, PartitionFor(entry.Series)); err != nil {
+		return fmt.Errorf("error converting events to Parquet: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package convert
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+const testEvents = `{"id":"tx-1","sequenceNumber":1,"occurredAt":"2024-05-10T12:00:00Z","seriesId":"2620066","events":[{"id":"ev-1","type":"series-started-game","action":"started","actor":{"type":"series","id":"2620066"},"target":{"type":"game","id":"g1"}}]}
+{"id":"tx-2","sequenceNumber":2,"occurredAt":"2024-05-10T12:05:00Z","seriesId":"2620066","events":[{"id":"ev-2","type":"player-killed-player","action":"killed","actor":{"type":"player","id":"p1","stateDelta":{"kills":1}},"target":{"type":"player","id":"p2"}}]}
+`
+
+func TestArchiveToParquet(t *testing.T) {
+	dir := t.TempDir()
+	// A renamed archive is partitioned by the series ID of its events.
+	archivePath := filepath.Join(dir, "2620066.redacted.zip")
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	zw := zip.NewWriter(file)
+	w, _ := zw.Create("events.jsonl")
+	w.Write([]byte(testEvents))
+	zw.Close()
+	file.Close()
+
+	root := filepath.Join(dir, "dataset")
+	path, count, err := ArchiveToParquet(archivePath, root, Partition{TitleID: "3", Tournament: "LCK Spring"})
+	if err != nil {
+		t.Fatalf("Failed to convert archive: %v", err)
+	}
+	expectedPath := filepath.Join(root, "title=3", "tournament=LCK%20Spring", "series=2620066", "events.parquet")
+	if path != expectedPath {
+		t.Fatalf("Expected Parquet file at %s, but got %s", expectedPath, path)
+	}
+	if count != 2 {
+		t.Fatalf("Expected 2 rows, but got %d", count)
+	}
+
+	rows, err := parquet.ReadFile[EventRow](path)
+	if err != nil {
+		t.Fatalf("Failed to read Parquet file: %v", err)
+	}
+	if len(rows) != 2 || rows[1].GameID != "g1" || rows[1].ActorStateDelta != `{"kills":1}` || rows[1].SeriesID != "2620066" {
+		t.Fatalf("Unexpected rows %+v", rows)
+	}
+}
@@ -0,0 +1,224 @@
+// Package draft extracts the drafts of a series from its events archive.
+//
+// A draft is the ordered list of picks and bans of a game: champion picks and bans in League
+// of Legends, agent selections in Valorant and map picks and bans of the map veto in CS2.
+// Draft actions are read from the events whose action is a pick, a ban or a selection and whose
+// target is a character or a map, in the order they occurred.
+package draft
+
+import (
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/replay"
+)
+
+const (
+	// Pick is the type of pick and selection actions.
+	Pick = "pick"
+
+	// Ban is the type of ban actions.
+	Ban = "ban"
+)
+
+// Action is a pick or a ban of a draft.
+type Action struct {
+	Game       int       `json:"game"`       // Game is the number of the game drafted, 0 for the map veto of the series.
+	Number     int       `json:"number"`     // Number is the position of the action in the draft of the game, starting at 1.
+	Type       string    `json:"type"`       // Type is either Pick or Ban.
+	Kind       string    `json:"kind"`       // Kind is the kind of the drafted item, "character" or "map".
+	TeamID     string    `json:"teamId"`     // TeamID is the ID of the team performing the action, if known.
+	Team       string    `json:"team"`       // Team is the name of the team performing the action, if known.
+	Side       string    `json:"side"`       // Side is the side of the team in the game, if known.
+	PlayerID   string    `json:"playerId"`   // PlayerID is the ID of the player performing the action, for player selections.
+	Player     string    `json:"player"`     // Player is the name of the player performing the action, for player selections.
+	ItemID     string    `json:"itemId"`     // ItemID is the ID of the drafted character or map.
+	Item       string    `json:"item"`       // Item is the name of the drafted character or map.
+	OccurredAt time.Time `json:"occurredAt"` // OccurredAt is the time of the action.
+}
+
+// Draft holds the draft actions of a series.
+type Draft struct {
+	SeriesID string   `json:"seriesId"` // SeriesID is the ID of the series.
+	Actions  []Action `json:"actions"`  // Actions are the draft actions, in order.
+}
+
+// Headers are the headers of the rows returned by Rows.
+var Headers = []string{"Game", "#", "Type", "Kind", "Team", "Side", "Player", "Drafted"}
+
+// ExtractArchive extracts the drafts of the series stored in an events archive.
+//
+// Parameters:
+//   - path: The path of the events archive downloaded with graphql.DownloadJSON.
+//
+// Returns:
+//   - *Draft: The draft actions of the series.
+//   - error: An error if the archive cannot be read.
+func ExtractArchive(path string) (*Draft, error) {
+	archive, err := events.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+	return Extract(archive.Events())
+}
+
+// Extract extracts the drafts of a series from its events.
+//
+// Character drafts that occur between games belong to the next game. Teams, sides and players
+// are resolved with the series state reconstructed up to each action.
+//
+// Parameters:
+//   - seq: An iterator over the events of the series, in order.
+//
+// Returns:
+//   - *Draft: The draft actions of the series.
+//   - error: An error if the events cannot be read.
+func Extract(seq iter.Seq2[events.Envelope, error]) (*Draft, error) {
+	d := &Draft{}
+	r := replay.New()
+	started := 0
+	inGame := false
+	numbers := map[int]int{}
+
+	for env, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		d.SeriesID = env.SeriesID
+		switch env.Type {
+		case "series-started-game":
+			started++
+			inGame = true
+		case "series-ended-game":
+			inGame = false
+		}
+		if err := r.Apply(env); err != nil {
+			return nil, err
+		}
+
+		actionType, ok := draftType(env.Action, env.Type)
+		kind := env.Target.Type
+		if !ok || (kind != "character" && kind != "map") {
+			continue
+		}
+
+		game := 0
+		if kind == "character" {
+			game = started
+			if !inGame {
+				game++
+			}
+		}
+		numbers[game]++
+		a := Action{
+			Game:       game,
+			Number:     numbers[game],
+			Type:       actionType,
+			Kind:       kind,
+			ItemID:     env.Target.ID,
+			Item:       env.Target.Name(),
+			OccurredAt: env.OccurredAt,
+		}
+		state, err := r.State()
+		if err != nil {
+			return nil, err
+		}
+		resolve(&a, env.Actor, state)
+		d.Actions = append(d.Actions, a)
+	}
+	return d, nil
+}
+
+// draftType returns the draft action type of an event action, if it is one.
+func draftType(action, eventType string) (string, bool) {
+	if action == "" {
+		// Older events only carry the action in their type, e.g. "team-banned-character".
+		parts := strings.Split(eventType, "-")
+		if len(parts) == 3 {
+			action = parts[1]
+		}
+	}
+	switch action {
+	case "picked", "selected", "locked":
+		return Pick, true
+	case "banned":
+		return Ban, true
+	}
+	return "", false
+}
+
+// resolve fills the team, side and player of an action from its actor and the series state.
+func resolve(a *Action, actor events.Entity, state *events.SeriesState) {
+	switch actor.Type {
+	case "team":
+		a.TeamID, a.Team = actor.ID, actor.Name()
+	case "player":
+		a.PlayerID, a.Player = actor.ID, actor.Name()
+	}
+	if state == nil {
+		return
+	}
+
+	for _, g := range state.Games {
+		if a.Game > 0 && g.SequenceNumber != a.Game {
+			continue
+		}
+		for _, t := range g.Teams {
+			if a.PlayerID != "" && a.TeamID == "" {
+				for _, p := range t.Players {
+					if p.ID == a.PlayerID {
+						a.TeamID = t.ID
+						if a.Player == "" {
+							a.Player = p.Name
+						}
+					}
+				}
+			}
+			if t.ID == a.TeamID && a.Game > 0 {
+				a.Side = t.Side
+				if a.Team == "" {
+					a.Team = t.Name
+				}
+			}
+		}
+	}
+	if a.Team == "" {
+		for _, t := range state.Teams {
+			if t.ID == a.TeamID {
+				a.Team = t.Name
+			}
+		}
+	}
+}
+
+// Rows returns one row per draft action, matching Headers.
+func (d *Draft) Rows() [][]string {
+	rows := make([][]string, len(d.Actions))
+	for i, a := range d.Actions {
+		game := "Veto"
+		if a.Game > 0 {
+			game = strconv.Itoa(a.Game)
+		}
+		item := a.Item
+		if item == "" {
+			item = a.ItemID
+		}
+		rows[i] = []string{game, strconv.Itoa(a.Number), a.Type, a.Kind, a.Team, a.Side, a.Player, item}
+	}
+	return rows
+}
+
+// Game returns the draft actions of a game, 0 for the map veto of the series.
+func (d *Draft) Game(number int) []Action {
+	var actions []Action
+	for _, a := range d.Actions {
+		if a.Game == number {
+			actions = append(actions, a)
+		}
+	}
+	return actions
+}
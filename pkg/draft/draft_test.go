@@ -0,0 +1,45 @@
+package draft
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+)
+
+const testEvents = `{"id":"tx-1","sequenceNumber":1,"occurredAt":"2024-05-10T11:00:00Z","seriesId":"2620066","events":[{"id":"ev-1","type":"team-banned-map","action":"banned","actor":{"type":"team","id":"t1"},"target":{"type":"map","id":"de_nuke","state":{"name":"Nuke"}},"seriesState":{"id":"2620066","teams":[{"id":"t1","name":"Team 1"},{"id":"t2","name":"Team 2"}],"games":[{"id":"g1","sequenceNumber":1,"teams":[{"id":"t1","name":"Team 1","side":"blue","players":[{"id":"p1","name":"Player 1"}]},{"id":"t2","name":"Team 2","side":"red"}]}]}}]}
+{"id":"tx-2","sequenceNumber":2,"occurredAt":"2024-05-10T11:01:00Z","seriesId":"2620066","events":[{"id":"ev-2","type":"team-banned-character","actor":{"type":"team","id":"t2"},"target":{"type":"character","id":"ahri","state":{"name":"Ahri"}}}]}
+{"id":"tx-3","sequenceNumber":3,"occurredAt":"2024-05-10T11:02:00Z","seriesId":"2620066","events":[{"id":"ev-3","type":"player-picked-character","action":"picked","actor":{"type":"player","id":"p1"},"target":{"type":"character","id":"azir","state":{"name":"Azir"}}}]}
+{"id":"tx-4","sequenceNumber":4,"occurredAt":"2024-05-10T11:05:00Z","seriesId":"2620066","events":[{"id":"ev-4","type":"series-started-game","target":{"type":"game","id":"g1"}},{"id":"ev-5","type":"player-killed-player","action":"killed","actor":{"type":"player","id":"p1"},"target":{"type":"player","id":"p2"}}]}
+`
+
+func TestExtract(t *testing.T) {
+	d, err := Extract(events.Flatten(events.ReadTransactions(strings.NewReader(testEvents))))
+	if err != nil {
+		t.Fatalf("Failed to extract draft: %v", err)
+	}
+	if len(d.Actions) != 3 {
+		t.Fatalf("Expected 3 draft actions, but got %d", len(d.Actions))
+	}
+
+	veto := d.Game(0)
+	if len(veto) != 1 || veto[0].Type != Ban || veto[0].Item != "Nuke" || veto[0].Team != "Team 1" {
+		t.Fatalf("Unexpected map veto %+v", veto)
+	}
+
+	game := d.Game(1)
+	if len(game) != 2 {
+		t.Fatalf("Expected 2 actions in game 1, but got %d", len(game))
+	}
+	if game[0].Type != Ban || game[0].Number != 1 || game[0].Team != "Team 2" || game[0].Side != "red" {
+		t.Fatalf("Unexpected ban %+v", game[0])
+	}
+	if game[1].Type != Pick || game[1].Player != "Player 1" || game[1].Team != "Team 1" || game[1].Side != "blue" {
+		t.Fatalf("Unexpected pick %+v", game[1])
+	}
+
+	rows := d.Rows()
+	if rows[0][0] != "Veto" || rows[2][7] != "Azir" {
+		t.Fatalf("Unexpected rows %v", rows)
+	}
+}
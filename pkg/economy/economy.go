@@ -0,0 +1,362 @@
+// Package economy extracts round by round economy timelines of CS2 and Valorant series from
+// their events archives.
+//
+// For every round, the money and the equipment value of each team are read from the series
+// state reconstructed just before the first kill of the round, once both teams have bought,
+// and the buy of each team is classified from its equipment value. The winner of the round
+// and its win condition are read from the state of the round when it ends.
+package economy
+
+import (
+	"fmt"
+	"iter"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/replay"
+)
+
+const (
+	// TitleCS2 is the GRID title ID of Counter-Strike 2.
+	TitleCS2 = "28"
+
+	// TitleValorant is the GRID title ID of Valorant.
+	TitleValorant = "6"
+)
+
+// Buy types, from the cheapest to the most expensive.
+const (
+	Pistol  = "Pistol"
+	Eco     = "Eco"
+	SemiEco = "Semi-eco"
+	SemiBuy = "Semi-buy"
+	FullBuy = "Full buy"
+)
+
+// Thresholds are the team equipment values from which buys are classified as semi-eco,
+// semi-buy and full buy, with the rounds that are pistol rounds.
+type Thresholds struct {
+	SemiEco      int   // SemiEco is the minimum equipment value of a semi-eco.
+	SemiBuy      int   // SemiBuy is the minimum equipment value of a semi-buy.
+	FullBuy      int   // FullBuy is the minimum equipment value of a full buy.
+	PistolRounds []int // PistolRounds are the numbers of the pistol rounds of a game.
+}
+
+// TitleThresholds are the buy thresholds of the supported titles.
+var TitleThresholds = map[string]Thresholds{
+	TitleCS2:      {SemiEco: 5000, SemiBuy: 10000, FullBuy: 20000, PistolRounds: []int{1, 13}},
+	TitleValorant: {SemiEco: 5000, SemiBuy: 10000, FullBuy: 18000, PistolRounds: []int{1, 13}},
+}
+
+// Round is the economy of a round.
+type Round struct {
+	Game         int         `json:"game"`         // Game is the number of the game in the series.
+	Number       int         `json:"number"`       // Number is the number of the round in the game.
+	Teams        []RoundTeam `json:"teams"`        // Teams are the economies of both teams.
+	Winner       string      `json:"winner"`       // Winner is the name of the team that won the round, if known.
+	WinCondition string      `json:"winCondition"` // WinCondition is how the round was won, e.g. "bombDefused", if known.
+}
+
+// RoundTeam is the economy of a team in a round.
+type RoundTeam struct {
+	ID      string `json:"id"`      // ID is the unique identifier of the team.
+	Name    string `json:"name"`    // Name is the name of the team.
+	Side    string `json:"side"`    // Side is the side of the team in the round.
+	Money   int    `json:"money"`   // Money is the unspent money of the team after buying.
+	Loadout int    `json:"loadout"` // Loadout is the value of the equipment of the team after buying.
+	Buy     string `json:"buy"`     // Buy is the classification of the buy of the team.
+	Won     bool   `json:"won"`     // Won indicates whether the team won the round.
+}
+
+// Economy is the economy timeline of a series.
+type Economy struct {
+	SeriesID string  `json:"seriesId"` // SeriesID is the ID of the series.
+	TitleID  string  `json:"titleId"`  // TitleID is the title the buys are classified for.
+	Rounds   []Round `json:"rounds"`   // Rounds are the rounds of every game, in order.
+}
+
+// Headers are the headers of the rows returned by Rows.
+var Headers = []string{"Game", "Round", "Team", "Side", "Money", "Equipment Value", "Buy Type", "Winner", "Win Condition"}
+
+// SummaryHeaders are the headers of the rows returned by SummaryRows.
+var SummaryHeaders = []string{"Team", "Buy Type", "Rounds", "Won", "Win %"}
+
+// Supported reports whether economy timelines can be extracted for a title.
+func Supported(titleID string) bool {
+	_, ok := TitleThresholds[titleID]
+	return ok
+}
+
+// ExtractArchive extracts the economy timeline of the series stored in an events archive.
+//
+// Parameters:
+//   - path: The path of the events archive downloaded with graphql.DownloadJSON.
+//   - titleID: The title of the series, either TitleCS2 or TitleValorant.
+//
+// Returns:
+//   - *Economy: The economy timeline of the series.
+//   - error: An error if the title is not supported or the archive cannot be read.
+func ExtractArchive(path, titleID string) (*Economy, error) {
+	archive, err := events.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+	return Extract(archive.Events(), titleID)
+}
+
+// Extract extracts the economy timeline of a series from its events.
+//
+// Rounds start with the events whose type ends with "-started-round". The economy of the
+// teams is captured before the first kill of the round, or at its end if there is none.
+//
+// Parameters:
+//   - seq: An iterator over the events of the series, in order.
+//   - titleID: The title of the series, either TitleCS2 or TitleValorant.
+//
+// Returns:
+//   - *Economy: The economy timeline of the series.
+//   - error: An error if the title is not supported or the events cannot be read.
+func Extract(seq iter.Seq2[events.Envelope, error], titleID string) (*Economy, error) {
+	thresholds, ok := TitleThresholds[titleID]
+	if !ok {
+		return nil, fmt.Errorf("economy timelines are only available for CS2 (title %s) and Valorant (title %s)", TitleCS2, TitleValorant)
+	}
+
+	e := &Economy{TitleID: titleID}
+	r := replay.New()
+	games := 0
+	var current *Round
+	var roundID, winnerID string
+	captured := false
+
+	finish := func() error {
+		if current == nil {
+			return nil
+		}
+		state, err := r.State()
+		if err != nil {
+			return err
+		}
+		if !captured {
+			capture(current, state)
+		}
+		settle(current, state, roundID, winnerID)
+		for i := range current.Teams {
+			current.Teams[i].Buy = thresholds.Classify(current.Number, current.Teams[i].Loadout)
+		}
+		e.Rounds = append(e.Rounds, *current)
+		current = nil
+		return nil
+	}
+
+	for env, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		e.SeriesID = env.SeriesID
+
+		switch {
+		case env.Type == "series-started-game" || env.Type == "series-ended-game" || strings.HasSuffix(env.Type, "-started-round"):
+			if err := finish(); err != nil {
+				return nil, err
+			}
+		case current != nil && !captured && env.Action == "killed" && env.Target.Type == "player":
+			state, err := r.State()
+			if err != nil {
+				return nil, err
+			}
+			capture(current, state)
+			captured = true
+		case current != nil && env.Type == "team-won-round":
+			winnerID = env.Actor.ID
+		}
+
+		if err := r.Apply(env); err != nil {
+			return nil, err
+		}
+
+		switch {
+		case env.Type == "series-started-game":
+			games++
+		case strings.HasSuffix(env.Type, "-started-round") && games > 0:
+			current = &Round{Game: games}
+			roundID, winnerID = env.Target.ID, ""
+			captured = false
+			state, err := r.State()
+			if err != nil {
+				return nil, err
+			}
+			current.Number = roundNumber(state, games, roundID)
+			if current.Number == 0 {
+				current.Number = len(e.Game(games)) + 1
+			}
+		}
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// gameState returns the state of a game of the series, or nil if it is unknown.
+func gameState(state *events.SeriesState, number int) *events.GameState {
+	if state == nil {
+		return nil
+	}
+	for i, g := range state.Games {
+		if g.SequenceNumber == number || (g.SequenceNumber == 0 && i+1 == number) {
+			return &state.Games[i]
+		}
+	}
+	return nil
+}
+
+// roundNumber returns the number of a round from its segment in the game state, or 0 if unknown.
+func roundNumber(state *events.SeriesState, game int, roundID string) int {
+	g := gameState(state, game)
+	if g == nil {
+		return 0
+	}
+	for _, s := range g.Segments {
+		if s.ID == roundID {
+			return s.SequenceNumber
+		}
+	}
+	return replay.Round(g)
+}
+
+// capture records the money and the equipment value of the teams of a round.
+func capture(round *Round, state *events.SeriesState) {
+	g := gameState(state, round.Game)
+	if g == nil {
+		return
+	}
+	round.Teams = round.Teams[:0]
+	for _, t := range g.Teams {
+		team := RoundTeam{ID: t.ID, Name: t.Name, Side: t.Side, Money: t.Money, Loadout: t.Loadout}
+		if team.Money == 0 && team.Loadout == 0 {
+			for _, p := range t.Players {
+				team.Money += p.Money
+				team.Loadout += p.Loadout
+			}
+		}
+		round.Teams = append(round.Teams, team)
+	}
+}
+
+// settle records the winner of a round and its win condition.
+func settle(round *Round, state *events.SeriesState, roundID, winnerID string) {
+	if g := gameState(state, round.Game); g != nil {
+		for _, s := range g.Segments {
+			if s.ID != roundID && s.SequenceNumber != round.Number {
+				continue
+			}
+			for _, t := range s.Teams {
+				if t.Won {
+					winnerID = t.ID
+					round.WinCondition = t.WinType
+				}
+			}
+		}
+	}
+	for i, t := range round.Teams {
+		if t.ID == winnerID && winnerID != "" {
+			round.Teams[i].Won = true
+			round.Winner = t.Name
+		}
+	}
+}
+
+// Classify classifies the buy of a team from the number of the round and its equipment value.
+func (t Thresholds) Classify(round, loadout int) string {
+	for _, r := range t.PistolRounds {
+		if r == round {
+			return Pistol
+		}
+	}
+	switch {
+	case loadout >= t.FullBuy:
+		return FullBuy
+	case loadout >= t.SemiBuy:
+		return SemiBuy
+	case loadout >= t.SemiEco:
+		return SemiEco
+	}
+	return Eco
+}
+
+// Game returns the rounds of a game.
+func (e *Economy) Game(number int) []Round {
+	var rounds []Round
+	for _, r := range e.Rounds {
+		if r.Game == number {
+			rounds = append(rounds, r)
+		}
+	}
+	return rounds
+}
+
+// Rows returns one row per round and team, matching Headers.
+func (e *Economy) Rows() [][]string {
+	var rows [][]string
+	for _, r := range e.Rounds {
+		for _, t := range r.Teams {
+			rows = append(rows, []string{
+				strconv.Itoa(r.Game),
+				strconv.Itoa(r.Number),
+				t.Name,
+				t.Side,
+				strconv.Itoa(t.Money),
+				strconv.Itoa(t.Loadout),
+				t.Buy,
+				r.Winner,
+				r.WinCondition,
+			})
+		}
+	}
+	return rows
+}
+
+// SummaryRows returns, for each team and buy type, the number of rounds played and won,
+// matching SummaryHeaders.
+func (e *Economy) SummaryRows() [][]string {
+	type key struct{ team, buy string }
+	played := map[key]int{}
+	won := map[key]int{}
+	for _, r := range e.Rounds {
+		for _, t := range r.Teams {
+			k := key{t.Name, t.Buy}
+			played[k]++
+			if t.Won {
+				won[k]++
+			}
+		}
+	}
+
+	order := map[string]int{Pistol: 0, Eco: 1, SemiEco: 2, SemiBuy: 3, FullBuy: 4}
+	keys := make([]key, 0, len(played))
+	for k := range played {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].team != keys[j].team {
+			return keys[i].team < keys[j].team
+		}
+		return order[keys[i].buy] < order[keys[j].buy]
+	})
+
+	rows := make([][]string, len(keys))
+	for i, k := range keys {
+		rows[i] = []string{
+			k.team,
+			k.buy,
+			strconv.Itoa(played[k]),
+			strconv.Itoa(won[k]),
+			fmt.Sprintf("%.0f%%", 100*float64(won[k])/float64(played[k])),
+		}
+	}
+	return rows
+}
@@ -0,0 +1,68 @@
+package economy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+)
+
+const testEvents = `{"id":"tx-1","sequenceNumber":1,"occurredAt":"2024-05-10T12:00:00Z","seriesId":"2700000","events":[{"id":"ev-1","type":"series-started-game","target":{"type":"game","id":"g1"},"seriesState":{"id":"2700000","games":[{"id":"g1","sequenceNumber":1,"started":true,"teams":[{"id":"t1","name":"Team 1","side":"ct","money":4000,"loadoutValue":0},{"id":"t2","name":"Team 2","side":"t","money":4000,"loadoutValue":0}]}]}}]}
+{"id":"tx-2","sequenceNumber":2,"occurredAt":"2024-05-10T12:00:05Z","seriesId":"2700000","events":[{"id":"ev-2","type":"game-started-round","target":{"type":"round","id":"r1"},"seriesStateDelta":{"games":[{"id":"g1","segments":[{"id":"r1","type":"round","sequenceNumber":1,"started":true}]}]}}]}
+{"id":"tx-3","sequenceNumber":3,"occurredAt":"2024-05-10T12:00:20Z","seriesId":"2700000","events":[{"id":"ev-3","type":"player-purchased-item","action":"purchased","seriesStateDelta":{"games":[{"id":"g1","teams":[{"id":"t1","money":500,"loadoutValue":3500},{"id":"t2","money":1000,"loadoutValue":3000}]}]}}]}
+{"id":"tx-4","sequenceNumber":4,"occurredAt":"2024-05-10T12:00:40Z","seriesId":"2700000","events":[{"id":"ev-4","type":"player-killed-player","action":"killed","target":{"type":"player","id":"p2"},"seriesStateDelta":{"games":[{"id":"g1","teams":[{"id":"t2","loadoutValue":2000}]}]}}]}
+{"id":"tx-5","sequenceNumber":5,"occurredAt":"2024-05-10T12:01:30Z","seriesId":"2700000","events":[{"id":"ev-5","type":"team-won-round","action":"won","actor":{"type":"team","id":"t1"},"target":{"type":"round","id":"r1"},"seriesStateDelta":{"games":[{"id":"g1","segments":[{"id":"r1","finished":true,"teams":[{"id":"t1","won":true,"winType":"opponentEliminated"},{"id":"t2","won":false}]}]}]}}]}
+{"id":"tx-6","sequenceNumber":6,"occurredAt":"2024-05-10T12:02:00Z","seriesId":"2700000","events":[{"id":"ev-6","type":"game-started-round","target":{"type":"round","id":"r2"},"seriesStateDelta":{"games":[{"id":"g1","segments":[{"id":"r2","type":"round","sequenceNumber":2,"started":true}],"teams":[{"id":"t1","money":1000,"loadoutValue":22000},{"id":"t2","money":9000,"loadoutValue":4000}]}]}}]}
+{"id":"tx-7","sequenceNumber":7,"occurredAt":"2024-05-10T12:03:30Z","seriesId":"2700000","events":[{"id":"ev-7","type":"team-won-round","action":"won","actor":{"type":"team","id":"t2"},"target":{"type":"round","id":"r2"}}]}
+{"id":"tx-8","sequenceNumber":8,"occurredAt":"2024-05-10T12:04:00Z","seriesId":"2700000","events":[{"id":"ev-8","type":"series-ended-game","target":{"type":"game","id":"g1"}}]}
+`
+
+func TestExtract(t *testing.T) {
+	e, err := Extract(events.Flatten(events.ReadTransactions(strings.NewReader(testEvents))), TitleCS2)
+	if err != nil {
+		t.Fatalf("Failed to extract economy: %v", err)
+	}
+	if len(e.Rounds) != 2 {
+		t.Fatalf("Expected 2 rounds, but got %d", len(e.Rounds))
+	}
+
+	first := e.Rounds[0]
+	if first.Number != 1 || first.Winner != "Team 1" || first.WinCondition != "opponentEliminated" {
+		t.Fatalf("Unexpected first round %+v", first)
+	}
+	if first.Teams[1].Loadout != 3000 || first.Teams[1].Money != 1000 || first.Teams[1].Buy != Pistol {
+		t.Fatalf("Expected economy to be captured before the first kill, but got %+v", first.Teams[1])
+	}
+
+	second := e.Rounds[1]
+	if second.Number != 2 || second.Winner != "Team 2" || !second.Teams[1].Won {
+		t.Fatalf("Unexpected second round %+v", second)
+	}
+	if second.Teams[0].Buy != FullBuy || second.Teams[1].Buy != Eco {
+		t.Fatalf("Unexpected buys %+v", second.Teams)
+	}
+
+	rows := e.Rows()
+	if len(rows) != 4 || rows[2][5] != "22000" || rows[2][6] != FullBuy {
+		t.Fatalf("Unexpected rows %v", rows)
+	}
+	summary := e.SummaryRows()
+	if len(summary) != 4 || summary[0][0] != "Team 1" || summary[0][1] != Pistol || summary[0][4] != "100%" {
+		t.Fatalf("Unexpected summary rows %v", summary)
+	}
+}
+
+func TestExtractUnsupportedTitle(t *testing.T) {
+	if _, err := Extract(events.Flatten(events.ReadTransactions(strings.NewReader(testEvents))), "3"); err == nil {
+		t.Fatalf("Expected an error for League of Legends, but got none")
+	}
+}
+
+func TestClassify(t *testing.T) {
+	thresholds := TitleThresholds[TitleValorant]
+	for loadout, expected := range map[int]string{1000: Eco, 6000: SemiEco, 12000: SemiBuy, 19000: FullBuy} {
+		if buy := thresholds.Classify(5, loadout); buy != expected {
+			t.Fatalf("Expected %s for %d, but got %s", expected, loadout, buy)
+		}
+	}
+}
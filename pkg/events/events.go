@@ -0,0 +1,246 @@
+// Package events streams GRID series events from the archives downloaded with graphql.DownloadJSON.
+//
+// An events archive is a ZIP file holding the events of a series as JSON lines. Each line is a
+// transaction, identified by a sequence number, that groups one or more events which occurred at
+// the same time. This package reads the transactions directly from the ZIP file, one at a time,
+// and exposes them through Go iterators, so that archives of several gigabytes can be processed
+// without extracting them to disk or loading them in memory.
+package events
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Transaction represents a line of an events file: a group of events published together.
+type Transaction struct {
+	ID             string    `json:"id"`             // ID is the unique identifier of the transaction.
+	CorrelationID  string    `json:"correlationId"`  // CorrelationID links the transaction to the data that caused it.
+	SequenceNumber int64     `json:"sequenceNumber"` // SequenceNumber is the position of the transaction in the series.
+	OccurredAt     time.Time `json:"occurredAt"`     // OccurredAt is the time the events occurred.
+	PublishedAt    time.Time `json:"publishedAt"`    // PublishedAt is the time the transaction was published.
+	SeriesID       string    `json:"seriesId"`       // SeriesID is the ID of the series.
+	Events         []Event   `json:"events"`         // Events are the events of the transaction.
+}
+
+// Event represents a single event of a transaction.
+//
+// The type of an event is made of the actor type, the action and the target type, for
+// example "player-killed-player" or "team-won-game". State deltas and full states are kept
+// as raw JSON so that they are only decoded by the consumers that need them.
+type Event struct {
+	ID                string          `json:"id"`                         // ID is the unique identifier of the event.
+	Type              string          `json:"type"`                       // Type is the event type.
+	Action            string          `json:"action"`                     // Action is the action performed by the actor.
+	IncludesFullState bool            `json:"includesFullState"`          // IncludesFullState indicates whether the states are complete.
+	Actor             Entity          `json:"actor"`                      // Actor is the entity performing the action.
+	Target            Entity          `json:"target"`                     // Target is the entity the action is performed on.
+	SeriesStateDelta  json.RawMessage `json:"seriesStateDelta,omitempty"` // SeriesStateDelta is the change of the series state.
+	SeriesState       json.RawMessage `json:"seriesState,omitempty"`      // SeriesState is the series state after the event.
+}
+
+// Entity represents the actor or the target of an event.
+type Entity struct {
+	Type       string          `json:"type"`                 // Type is the entity type, e.g. "player", "team" or "game".
+	ID         string          `json:"id"`                   // ID is the unique identifier of the entity.
+	StateDelta json.RawMessage `json:"stateDelta,omitempty"` // StateDelta is the change of the entity state.
+	State      json.RawMessage `json:"state,omitempty"`      // State is the entity state after the event.
+}
+
+// Envelope is an event together with the transaction it belongs to.
+type Envelope struct {
+	TransactionID  string    `json:"transactionId"`  // TransactionID is the ID of the transaction.
+	SequenceNumber int64     `json:"sequenceNumber"` // SequenceNumber is the sequence number of the transaction.
+	OccurredAt     time.Time `json:"occurredAt"`     // OccurredAt is the time the event occurred.
+	SeriesID       string    `json:"seriesId"`       // SeriesID is the ID of the series.
+	Index          int       `json:"index"`          // Index is the position of the event in its transaction.
+	Event
+}
+
+// Archive is an events archive opened for reading.
+type Archive struct {
+	zr    *zip.ReadCloser
+	files []*zip.File
+}
+
+// Open opens an events archive.
+//
+// Parameters:
+//   - path: The path of the ZIP file downloaded with graphql.DownloadJSON.
+//
+// Returns:
+//   - *Archive: The opened archive. It must be closed after use.
+//   - error: An error if the file is not a ZIP file or holds no events file.
+func Open(path string) (*Archive, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening events archive: %v", err)
+	}
+
+	var files []*zip.File
+	for _, f := range zr.File {
+		if isEventsFile(f.Name) {
+			files = append(files, f)
+		}
+	}
+	if len(files) == 0 {
+		zr.Close()
+		return nil, fmt.Errorf("no events file found in %s", path)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+
+	return &Archive{zr: zr, files: files}, nil
+}
+
+// isEventsFile reports whether a ZIP entry holds events.
+func isEventsFile(name string) bool {
+	if strings.HasSuffix(name, "/") {
+		return false
+	}
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jsonl", ".json", ".ndjson":
+		return true
+	}
+	return false
+}
+
+// Close closes the archive.
+func (a *Archive) Close() error {
+	return a.zr.Close()
+}
+
+// Files returns the names of the events files of the archive, in reading order.
+func (a *Archive) Files() []string {
+	names := make([]string, len(a.files))
+	for i, f := range a.files {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// Transactions returns an iterator over the transactions of the archive.
+//
+// The iteration stops after the first error, which is yielded with a zero transaction.
+func (a *Archive) Transactions() iter.Seq2[Transaction, error] {
+	return func(yield func(Transaction, error) bool) {
+		for _, f := range a.files {
+			rc, err := f.Open()
+			if err != nil {
+				yield(Transaction{}, fmt.Errorf("error opening %s: %v", f.Name, err))
+				return
+			}
+			ok := true
+			for tx, err := range ReadTransactions(rc) {
+				if err != nil {
+					err = fmt.Errorf("%s: %v", f.Name, err)
+				}
+				if !yield(tx, err) || err != nil {
+					ok = false
+					break
+				}
+			}
+			rc.Close()
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// Events returns an iterator over the events of the archive, in order.
+//
+// The iteration stops after the first error, which is yielded with a zero envelope.
+func (a *Archive) Events() iter.Seq2[Envelope, error] {
+	return Flatten(a.Transactions())
+}
+
+// ReadTransactions returns an iterator over the transactions read from r.
+//
+// The input holds one JSON transaction per line. A JSON array of transactions is also accepted.
+// The iteration stops after the first error, which is yielded with a zero transaction.
+func ReadTransactions(r io.Reader) iter.Seq2[Transaction, error] {
+	return func(yield func(Transaction, error) bool) {
+		br := bufio.NewReaderSize(r, 1<<20)
+		dec := json.NewDecoder(br)
+
+		isArray, err := startsWithArray(br)
+		if err != nil {
+			if err != io.EOF {
+				yield(Transaction{}, fmt.Errorf("error reading events: %v", err))
+			}
+			return
+		}
+		if isArray {
+			if _, err := dec.Token(); err != nil {
+				yield(Transaction{}, fmt.Errorf("error reading events: %v", err))
+				return
+			}
+		}
+
+		for line := 1; ; line++ {
+			if isArray && !dec.More() {
+				return
+			}
+			var tx Transaction
+			err := dec.Decode(&tx)
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(Transaction{}, fmt.Errorf("error decoding transaction %d: %v", line, err))
+				return
+			}
+			if !yield(tx, nil) {
+				return
+			}
+		}
+	}
+}
+
+// startsWithArray reports whether the next non-space byte of br opens a JSON array.
+func startsWithArray(br *bufio.Reader) (bool, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return false, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b == '[', br.UnreadByte()
+	}
+}
+
+// Flatten converts an iterator over transactions into an iterator over their events.
+func Flatten(transactions iter.Seq2[Transaction, error]) iter.Seq2[Envelope, error] {
+	return func(yield func(Envelope, error) bool) {
+		for tx, err := range transactions {
+			if err != nil {
+				yield(Envelope{}, err)
+				return
+			}
+			for i, e := range tx.Events {
+				env := Envelope{
+					TransactionID:  tx.ID,
+					SequenceNumber: tx.SequenceNumber,
+					OccurredAt:     tx.OccurredAt,
+					SeriesID:       tx.SeriesID,
+					Index:          i,
+					Event:          e,
+				}
+				if !yield(env, nil) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package events
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testEvents = `{"id":"tx-1","sequenceNumber":1,"occurredAt":"2024-05-10T12:00:00Z","seriesId":"2620066","events":[{"id":"ev-1","type":"series-started-game","action":"started","actor":{"type":"series","id":"2620066"},"target":{"type":"game","id":"game-1"}}]}
+{"id":"tx-2","sequenceNumber":2,"occurredAt":"2024-05-10T12:05:00Z","seriesId":"2620066","events":[{"id":"ev-2","type":"player-killed-player","action":"killed","actor":{"type":"player","id":"p1","stateDelta":{"kills":1}},"target":{"type":"player","id":"p2"}},{"id":"ev-3","type":"team-won-game","action":"won","actor":{"type":"team","id":"t1"},"target":{"type":"game","id":"game-1"}}]}
+`
+
+// writeArchive writes a ZIP file holding the given events file content and returns its path.
+func writeArchive(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "2620066.zip")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+	w, err := zw.Create("events_2620066_grid.jsonl")
+	if err != nil {
+		t.Fatalf("Failed to create archive entry: %v", err)
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		t.Fatalf("Failed to write archive entry: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to close archive: %v", err)
+	}
+	return path
+}
+
+func TestArchiveEvents(t *testing.T) {
+	archive, err := Open(writeArchive(t, testEvents))
+	if err != nil {
+		t.Fatalf("Failed to open archive: %v", err)
+	}
+	defer archive.Close()
+
+	var types []string
+	for env, err := range archive.Events() {
+		if err != nil {
+			t.Fatalf("Failed to read events: %v", err)
+		}
+		types = append(types, env.Type)
+		if env.ID == "ev-3" && (env.SequenceNumber != 2 || env.Index != 1 || env.OccurredAt.Minute() != 5) {
+			t.Fatalf("Unexpected envelope for ev-3: %+v", env)
+		}
+		if env.ID == "ev-2" && string(env.Actor.StateDelta) != `{"kills":1}` {
+			t.Fatalf("Expected actor state delta to be kept, but got %s", env.Actor.StateDelta)
+		}
+	}
+	expected := "series-started-game,player-killed-player,team-won-game"
+	if strings.Join(types, ",") != expected {
+		t.Fatalf("Expected event types %s, but got %s", expected, strings.Join(types, ","))
+	}
+}
+
+func TestReadTransactionsStopsOnError(t *testing.T) {
+	count := 0
+	var lastErr error
+	for _, err := range ReadTransactions(strings.NewReader(testEvents + "{not json}\n")) {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		count++
+	}
+	if count != 2 || lastErr == nil {
+		t.Fatalf("Expected 2 transactions followed by an error, but got %d and %v", count, lastErr)
+	}
+}
+
+func TestReadTransactionsArray(t *testing.T) {
+	input := "[" + strings.Join(strings.Split(strings.TrimSpace(testEvents), "\n"), ",") + "]"
+	count := 0
+	for _, err := range ReadTransactions(strings.NewReader(input)) {
+		if err != nil {
+			t.Fatalf("Failed to read transactions: %v", err)
+		}
+		count++
+	}
+	if count != 2 {
+		t.Fatalf("Expected 2 transactions, but got %d", count)
+	}
+}
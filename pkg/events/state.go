@@ -0,0 +1,156 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SeriesState is the subset of the GRID series state carried by events.
+//
+// Fields that are absent from a state or a state delta are left at their zero value.
+type SeriesState struct {
+	ID       string      `json:"id"`       // ID is the ID of the series.
+	Started  bool        `json:"started"`  // Started indicates whether the series has started.
+	Finished bool        `json:"finished"` // Finished indicates whether the series has finished.
+	Teams    []TeamState `json:"teams"`    // Teams are the teams of the series, with the number of games won as score.
+	Games    []GameState `json:"games"`    // Games are the games of the series, in order.
+}
+
+// GameState is the state of a game of a series.
+type GameState struct {
+	ID             string         `json:"id"`             // ID is the unique identifier of the game.
+	SequenceNumber int            `json:"sequenceNumber"` // SequenceNumber is the number of the game in the series, starting at 1.
+	Started        bool           `json:"started"`        // Started indicates whether the game has started.
+	Finished       bool           `json:"finished"`       // Finished indicates whether the game has finished.
+	Map            MapState       `json:"map"`            // Map is the map the game is played on.
+	Clock          ClockState     `json:"clock"`          // Clock is the in-game clock.
+	Teams          []TeamState    `json:"teams"`          // Teams are the teams playing the game.
+	Segments       []SegmentState `json:"segments"`       // Segments are the rounds of the game in tactical shooters.
+}
+
+// SegmentState is the state of a segment of a game, e.g. a round in CS2 or Valorant.
+type SegmentState struct {
+	ID             string      `json:"id"`             // ID is the identifier of the segment.
+	Type           string      `json:"type"`           // Type is the type of the segment, e.g. "round".
+	SequenceNumber int         `json:"sequenceNumber"` // SequenceNumber is the number of the segment in the game, starting at 1.
+	Started        bool        `json:"started"`        // Started indicates whether the segment has started.
+	Finished       bool        `json:"finished"`       // Finished indicates whether the segment has finished.
+	Teams          []TeamState `json:"teams"`          // Teams are the teams playing the segment.
+}
+
+// MapState identifies the map of a game.
+type MapState struct {
+	ID   string `json:"id"`   // ID is the identifier of the map.
+	Name string `json:"name"` // Name is the name of the map.
+}
+
+// ClockState is the in-game clock of a game.
+type ClockState struct {
+	CurrentSeconds float64 `json:"currentSeconds"` // CurrentSeconds is the game time in seconds.
+	Ticking        bool    `json:"ticking"`        // Ticking indicates whether the clock is running.
+}
+
+// TeamState is the state of a team, either in the series or in a game.
+type TeamState struct {
+	ID         string           `json:"id"`           // ID is the unique identifier of the team.
+	Name       string           `json:"name"`         // Name is the name of the team.
+	Side       string           `json:"side"`         // Side is the side the team plays on in a game.
+	Won        bool             `json:"won"`          // Won indicates whether the team won the series or the game.
+	Score      int              `json:"score"`        // Score is the number of games won in a series, or the score in a game.
+	Kills      int              `json:"kills"`        // Kills is the number of kills of the team.
+	Deaths     int              `json:"deaths"`       // Deaths is the number of deaths of the team.
+	NetWorth   int              `json:"netWorth"`     // NetWorth is the total gold or equipment value of the team.
+	Money      int              `json:"money"`        // Money is the unspent money of the team in tactical shooters.
+	Loadout    int              `json:"loadoutValue"` // Loadout is the value of the equipment of the team in tactical shooters.
+	WinType    string           `json:"winType"`      // WinType is how the team won a segment, e.g. "bombDefused".
+	Objectives []ObjectiveState `json:"objectives"`   // Objectives are the objectives completed by the team.
+	Players    []PlayerState    `json:"players"`      // Players are the players of the team in a game.
+}
+
+// ObjectiveState counts the completions of an objective, e.g. towers destroyed or dragons slain.
+type ObjectiveState struct {
+	ID              string `json:"id"`              // ID is the identifier of the objective.
+	Type            string `json:"type"`            // Type is the type of the objective.
+	CompletionCount int    `json:"completionCount"` // CompletionCount is the number of times the objective was completed.
+}
+
+// PlayerState is the state of a player in a game.
+type PlayerState struct {
+	ID               string         `json:"id"`               // ID is the unique identifier of the player.
+	Name             string         `json:"name"`             // Name is the name of the player.
+	Kills            int            `json:"kills"`            // Kills is the number of kills of the player.
+	Deaths           int            `json:"deaths"`           // Deaths is the number of deaths of the player.
+	KillAssistsGiven int            `json:"killAssistsGiven"` // KillAssistsGiven is the number of assists of the player.
+	NetWorth         int            `json:"netWorth"`         // NetWorth is the total gold or equipment value of the player.
+	Money            int            `json:"money"`            // Money is the unspent money of the player in tactical shooters.
+	Loadout          int            `json:"loadoutValue"`     // Loadout is the value of the equipment of the player in tactical shooters.
+	Alive            bool           `json:"alive"`            // Alive indicates whether the player is alive.
+	Character        CharacterState `json:"character"`        // Character is the champion or agent played.
+}
+
+// CharacterState identifies the champion or agent played by a player.
+type CharacterState struct {
+	ID   string `json:"id"`   // ID is the identifier of the character.
+	Name string `json:"name"` // Name is the name of the character.
+}
+
+// Name returns the name found in the state or the state delta of the entity, if any.
+func (e Entity) Name() string {
+	var named struct {
+		Name string `json:"name"`
+	}
+	if len(e.State) > 0 && json.Unmarshal(e.State, &named) == nil && named.Name != "" {
+		return named.Name
+	}
+	if len(e.StateDelta) > 0 && json.Unmarshal(e.StateDelta, &named) == nil {
+		return named.Name
+	}
+	return ""
+}
+
+// Label returns a short description of the entity, its name if known or its type and ID.
+func (e Entity) Label() string {
+	if name := e.Name(); name != "" {
+		return name
+	}
+	if e.ID == "" {
+		return e.Type
+	}
+	return e.Type + ":" + e.ID
+}
+
+// State decodes the series state carried by the event.
+//
+// Returns:
+//   - *SeriesState: The decoded series state, or nil if the event does not carry one.
+//   - error: An error if the series state cannot be decoded.
+func (e Event) State() (*SeriesState, error) {
+	if len(e.SeriesState) == 0 {
+		return nil, nil
+	}
+	var state SeriesState
+	if err := json.Unmarshal(e.SeriesState, &state); err != nil {
+		return nil, fmt.Errorf("error decoding series state of event %s: %v", e.ID, err)
+	}
+	return &state, nil
+}
+
+// Game returns the state of the game with the given ID, or nil if there is none.
+func (s *SeriesState) Game(id string) *GameState {
+	for i := range s.Games {
+		if s.Games[i].ID == id {
+			return &s.Games[i]
+		}
+	}
+	return nil
+}
+
+// Winner returns the team that won the game, or nil if there is none yet.
+func (g *GameState) Winner() *TeamState {
+	for i := range g.Teams {
+		if g.Teams[i].Won {
+			return &g.Teams[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/graphql"
+)
+
+// Column is a column of the series table, displayed in the TUI and written by exports.
+type Column struct {
+	Key    string                      // Key identifies the column in the configuration, e.g. "team_one".
+	Title  string                      // Title is the header of the column.
+	Value  func(graphql.Series) string // Value returns the cell of a series in the column.
+	Result bool                        // Result reports whether the column reads the result of the series, see graphql.Series.Result.
+}
+
+// SeriesColumns are the available columns of the series table, in their default order.
+var SeriesColumns = []Column{
+	{Key: "start_time", Title: "Start Time", Value: func(s graphql.Series) string { return s.StartTimeScheduled }},
+	{Key: "series_id", Title: "Serie ID", Value: func(s graphql.Series) string { return s.ID }},
+	{Key: "tournament", Title: "Tournament", Value: func(s graphql.Series) string { return s.Tournament.Name }},
+	{Key: "team_one", Title: "Team One", Value: func(s graphql.Series) string { return teamInfo(s, 0).Name }},
+	{Key: "team_two", Title: "Team Two", Value: func(s graphql.Series) string { return teamInfo(s, 1).Name }},
+	{Key: "format", Title: "Format", Value: func(s graphql.Series) string { return s.Format.NameShortened }},
+	{Key: "tournament_short", Title: "Tournament (Short)", Value: func(s graphql.Series) string { return s.Tournament.NameShortened }},
+	{Key: "tournament_id", Title: "Tournament ID", Value: func(s graphql.Series) string { return s.Tournament.ID }},
+	{Key: "team_one_id", Title: "Team One ID", Value: func(s graphql.Series) string { return teamInfo(s, 0).ID }},
+	{Key: "team_two_id", Title: "Team Two ID", Value: func(s graphql.Series) string { return teamInfo(s, 1).ID }},
+	{Key: "title", Title: "Title", Value: func(s graphql.Series) string {
+		if s.Title.NameShortened != "" {
+			return s.Title.NameShortened
+		}
+		return s.Title.Name
+	}},
+	{Key: "title_id", Title: "Title ID", Value: func(s graphql.Series) string { return s.Title.ID }},
+	{Key: "score", Title: "Score", Result: true, Value: func(s graphql.Series) string {
+		if s.Result == nil {
+			return ""
+		}
+		return fmt.Sprintf("%d-%d", s.Result.Scores[teamInfo(s, 0).ID], s.Result.Scores[teamInfo(s, 1).ID])
+	}},
+	{Key: "winner", Title: "Winner", Result: true, Value: func(s graphql.Series) string {
+		if s.Result == nil {
+			return ""
+		}
+		return s.Result.Winner
+	}},
+}
+
+// DefaultColumnKeys are the keys of the columns of the series table when none are configured.
+var DefaultColumnKeys = []string{"start_time", "series_id", "tournament", "team_one", "team_two"}
+
+// SeriesHeaders are the headers of the default columns of the series table.
+var SeriesHeaders = Headers(DefaultColumns())
+
+// DefaultColumns returns the default columns of the series table.
+func DefaultColumns() []Column {
+	columns, _ := ParseColumns(DefaultColumnKeys)
+	return columns
+}
+
+// ParseColumns looks up columns of the series table by key, ignoring case.
+//
+// Parameters:
+//   - keys: The keys of the columns, in order. An empty list selects the default columns.
+//
+// Returns:
+//   - []Column: The columns, in the order of the keys.
+//   - error: An error if a key is unknown or repeated.
+func ParseColumns(keys []string) ([]Column, error) {
+	if len(keys) == 0 {
+		keys = DefaultColumnKeys
+	}
+	columns := make([]Column, 0, len(keys))
+	seen := map[string]bool{}
+	for _, key := range keys {
+		key = strings.ToLower(strings.TrimSpace(key))
+		column, ok := LookupColumn(key)
+		if !ok {
+			return nil, fmt.Errorf("unknown series column %q; available columns are %s", key, strings.Join(ColumnKeys(SeriesColumns), ", "))
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("series column %q is repeated", key)
+		}
+		seen[key] = true
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// LookupColumn returns the column of the series table with a key.
+func LookupColumn(key string) (Column, bool) {
+	for _, c := range SeriesColumns {
+		if c.Key == key {
+			return c, true
+		}
+	}
+	return Column{}, false
+}
+
+// ColumnKeys returns the keys of columns.
+func ColumnKeys(columns []Column) []string {
+	keys := make([]string, len(columns))
+	for i, c := range columns {
+		keys[i] = c.Key
+	}
+	return keys
+}
+
+// Headers returns the titles of columns.
+func Headers(columns []Column) []string {
+	headers := make([]string, len(columns))
+	for i, c := range columns {
+		headers[i] = c.Title
+	}
+	return headers
+}
+
+// NeedsResults reports whether any of the columns reads the result of the series, which must
+// then be loaded before the rows are built.
+func NeedsResults(columns []Column) bool {
+	for _, c := range columns {
+		if c.Result {
+			return true
+		}
+	}
+	return false
+}
+
+// ColumnRow returns the cells of a series in columns.
+func ColumnRow(s graphql.Series, columns []Column) []string {
+	row := make([]string, len(columns))
+	for i, c := range columns {
+		row[i] = c.Value(s)
+	}
+	return row
+}
+
+// ColumnRows returns the cells of series in columns, one row per series.
+func ColumnRows(series []graphql.Series, columns []Column) [][]string {
+	rows := make([][]string, len(series))
+	for i, s := range series {
+		rows[i] = ColumnRow(s, columns)
+	}
+	return rows
+}
+
+// teamInfo returns the team of a series at an index, or an empty team if the series has fewer teams.
+func teamInfo(s graphql.Series, index int) graphql.TeamInfo {
+	if index < len(s.Teams) {
+		return s.Teams[index].BaseInfo
+	}
+	return graphql.TeamInfo{}
+}
@@ -1,67 +1,192 @@
+// Package export writes the tables of the application to files and other writers.
+//
+// Exporters only write to an io.Writer and return their errors, so that they can be used from
+// the TUI, from headless commands, from scripts and from tests alike. Choosing where to save a
+// file is left to the caller.
 package export
 
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"time"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/table"
-	"github.com/sqweek/dialog"
 )
 
-// ExportData exports the provided data to a CSV file selected by the user.
-//
-// The CSV file will include the headers "Start Time", "Serie ID", "Tournament", "Blue Team", and "Red Team".
-// Each row in the provided data will be written as a record in the CSV file.
+// Format is a file format tables can be exported to.
+type Format string
+
+const (
+	// CSV exports tables as comma separated values with a header row.
+	CSV Format = "csv"
+
+	// JSON exports an indented JSON array, of series objects or of rows keyed by header.
+	JSON Format = "json"
+
+	// NDJSON exports newline delimited JSON, one series object or row per line.
+	NDJSON Format = "ndjson"
+)
+
+// Formats are the supported export formats.
+var Formats = []Format{CSV, JSON, NDJSON, XLSX, ICS, Markdown, HTML, SQLite}
+
+// ParseFormat parses the name of an export format, ignoring case.
 //
 // Parameters:
-//   - data: A slice of table rows containing the data to be exported. Each row is expected to have 5 elements:
-//   - Start Time (string): The start time of the game.
-//   - Serie ID (string): The unique identifier of the series.
-//   - Tournament (string): The name of the tournament.
-//   - Blue Team (string): The name of the blue team.
-//   - Red Team (string): The name of the red team.
+//   - name: The name of the format, e.g. "csv".
 //
-// The function opens a dialog for the user to select the save location and file name for the CSV file.
-// It writes the headers to the file, followed by each row of data. If an error occurs during file creation or writing,
-// the function prints an error message to the console. Upon successful completion, a confirmation message is printed
-// and the function pauses for 1 second.
-func ExportData(data []table.Row) {
-	filePath, err := dialog.File().Title("Save CSV File").Save()
-	if err != nil || filePath == "" {
-		fmt.Println("File save canceled or error occurred.")
-		return
+// Returns:
+//   - Format: The format.
+//   - error: An error if the format is not supported.
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats {
+		if strings.EqualFold(string(f), name) {
+			return f, nil
+		}
 	}
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("unknown export format %q; supported formats are %s", name, strings.Join(names, ", "))
+}
 
-	if filepath.Ext(filePath) != ".csv" {
-		filePath += ".csv"
+// Extension returns the file extension of a format, including the leading dot.
+func (f Format) Extension() string {
+	return "." + string(f)
+}
+
+// SeriesRows converts rows of the series table in the default columns to exported rows.
+//
+// Each row is expected to have at least 5 elements: the start time, the series ID, the
+// tournament and the names of both teams. Other elements, such as the saved marker, are dropped.
+//
+// Returns:
+//   - [][]string: The exported rows.
+//   - error: An error if a row has fewer elements than the default columns.
+func SeriesRows(data []table.Row) ([][]string, error) {
+	rows := make([][]string, len(data))
+	for i, row := range data {
+		if len(row) < len(SeriesHeaders) {
+			return nil, fmt.Errorf("row %d has %d cells, but the default columns of the series table have %d", i+1, len(row), len(SeriesHeaders))
+		}
+		rows[i] = []string{row[0], row[1], row[2], row[3], row[4]}
 	}
+	return rows, nil
+}
 
-	file, err := os.Create(filePath)
+// Export writes rows of the series table in the default columns in the CSV, JSON, NDJSON and
+// XLSX formats with the headers SeriesHeaders, as the events of a calendar in the ICS format,
+// or as a schedule report in the Markdown and HTML formats.
+//
+// Deprecated: The series table may have other columns than the default ones. Use
+// ExportSeriesColumns with the series and their columns instead.
+//
+// Parameters:
+//   - w: The destination of the export.
+//   - data: The rows of the series table.
+//   - format: The format of the export.
+//
+// Returns:
+//   - error: An error if the format is not supported, a row has fewer cells than the default
+//     columns or the rows cannot be written.
+func Export(w io.Writer, data []table.Row, format Format) error {
+	rows, err := SeriesRows(data)
 	if err != nil {
-		fmt.Printf("Error creating file: %v", err)
-		return
+		return err
 	}
-	defer file.Close()
+	switch format {
+	case ICS:
+		return WriteICS(w, seriesFromRows(rows), ICSOptions{})
+	case Markdown, HTML:
+		return WriteReport(w, seriesFromRows(rows), format, ReportOptions{})
+	}
+	return ExportTable(w, SeriesHeaders, rows, format)
+}
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+// ExportTable writes a table with arbitrary headers.
+//
+// Parameters:
+//   - w: The destination of the export.
+//   - headers: The headers of the table.
+//   - rows: The rows of the table, each with as many elements as there are headers.
+//   - format: The format of the export.
+//
+// Returns:
+//   - error: An error if the format is not supported or the table cannot be written.
+func ExportTable(w io.Writer, headers []string, rows [][]string, format Format) error {
+	switch format {
+	case CSV:
+		return writeCSV(w, headers, rows)
+	case JSON, NDJSON:
+		objects := make([]rowObject, len(rows))
+		for i, row := range rows {
+			objects[i] = rowObject{headers: headers, values: row}
+		}
+		return writeJSON(w, objects, format)
+	case XLSX:
+		return WriteXLSX(w, []Sheet{{Name: "Export", Headers: headers, Rows: rows}}, XLSXOptions{})
+	case ICS, Markdown, HTML:
+		return fmt.Errorf("only series can be exported to %s", format)
+	case SQLite:
+		return fmt.Errorf("%s exports are written to a database file with UpsertSQLite", format)
+	}
+	return fmt.Errorf("unknown export format %q", format)
+}
 
-	headers := []string{"Start Time", "Serie ID", "Tournament", "Blue Team", "Red Team"}
+// writeCSV writes a table as CSV.
+func writeCSV(w io.Writer, headers []string, rows [][]string) error {
+	writer := csv.NewWriter(w)
 	if err := writer.Write(headers); err != nil {
-		fmt.Printf("Error writing headers to CSV: %v", err)
-		return
+		return fmt.Errorf("error writing headers to CSV: %v", err)
 	}
-
-	for _, row := range data {
-		record := []string{row[0], row[1], row[2], row[3], row[4]}
+	for _, record := range rows {
 		if err := writer.Write(record); err != nil {
-			fmt.Printf("Error writing record to CSV: %v", err)
-			return
+			return fmt.Errorf("error writing record to CSV: %v", err)
 		}
 	}
-	fmt.Printf("Data successfully exported to %s\n", filePath)
-	time.Sleep(1 * time.Second)
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error writing CSV: %v", err)
+	}
+	return nil
+}
+
+// Path returns the path a file is exported to, adding the extension of the format if the path
+// has none.
+func Path(path string, format Format) string {
+	if filepath.Ext(path) == "" {
+		return path + format.Extension()
+	}
+	return path
+}
+
+// WriteFile exports data to a file, creating or truncating it.
+//
+// Parameters:
+//   - path: The path of the file. The extension of the format is added if the path has none.
+//   - format: The format of the export.
+//   - write: The exporter writing the data to the file, e.g. a call to ExportTable.
+//
+// Returns:
+//   - string: The path of the written file.
+//   - error: An error if the file cannot be created or written.
+func WriteFile(path string, format Format, write func(w io.Writer) error) (string, error) {
+	path = Path(path, format)
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("error creating file: %v", err)
+	}
+	defer file.Close()
+
+	if err := write(file); err != nil {
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("error writing file: %v", err)
+	}
+	return path, nil
 }
@@ -1,18 +1,353 @@
 package export
 
 import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/graphql"
 )
 
-func TestExportData(t *testing.T) {
+var testSeries = []graphql.Series{{
+	ID:                 "2620066",
+	StartTimeScheduled: "2024-05-10T11:00:00Z",
+	Tournament:         graphql.Tournament{ID: "825", Name: "LEC Spring 2024"},
+	Format:             graphql.Format{NameShortened: "Bo3"},
+	Teams:              []graphql.SeriesTeam{{BaseInfo: graphql.TeamInfo{ID: "t1", Name: "Team 1"}}, {BaseInfo: graphql.TeamInfo{ID: "t2", Name: "Team 2"}}},
+}}
+
+func TestExport(t *testing.T) {
 	rows := []table.Row{
-		{"2024-05-10T00:00:00Z", "1", "Tournament 1", "Team 1", "Team 2"},
+		{"2024-05-10T00:00:00Z", "1", "Tournament 1", "Team 1", "Team 2", "✓"},
+	}
+	var buf bytes.Buffer
+	if err := Export(&buf, rows, CSV); err != nil {
+		t.Fatalf("Failed to export data: %v", err)
+	}
+	expected := "Start Time,Serie ID,Tournament,Team One,Team Two\n2024-05-10T00:00:00Z,1,Tournament 1,Team 1,Team 2\n"
+	if buf.String() != expected {
+		t.Fatalf("Expected %q, but got %q", expected, buf.String())
+	}
+
+	if err := Export(&buf, rows, Format("pdf")); err == nil {
+		t.Fatalf("Expected an error for an unknown format")
+	}
+	if err := Export(&buf, []table.Row{{"2024-05-10T00:00:00Z", "1"}}, CSV); err == nil {
+		t.Fatalf("Expected an error for a row with fewer cells than the default columns")
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	path, err := WriteFile(filepath.Join(t.TempDir(), "games"), CSV, func(w io.Writer) error {
+		return ExportTable(w, []string{"A", "B"}, [][]string{{"1", "2"}}, CSV)
+	})
+	if err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if filepath.Ext(path) != ".csv" {
+		t.Fatalf("Expected the .csv extension to be added, but got %s", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected file %s to be created, but got %v", path, err)
+	}
+	if string(data) != "A,B\n1,2\n" {
+		t.Fatalf("Unexpected file content %q", data)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, err := ParseFormat("CSV"); err != nil || f != CSV {
+		t.Fatalf("Expected format csv, but got %q (%v)", f, err)
+	}
+	if _, err := ParseFormat("pdf"); err == nil {
+		t.Fatalf("Expected an error for an unknown format")
+	}
+}
+
+func TestExportSeries(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportSeries(&buf, append(testSeries, testSeries...), NDJSON); err != nil {
+		t.Fatalf("Failed to export series: %v", err)
+	}
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, but got %d", len(lines))
+	}
+	var s graphql.Series
+	if err := json.Unmarshal(lines[0], &s); err != nil {
+		t.Fatalf("Failed to decode series: %v", err)
+	}
+	if s.Format.NameShortened != "Bo3" || s.Tournament.ID != "825" || s.Teams[1].BaseInfo.ID != "t2" {
+		t.Fatalf("Unexpected series %+v", s)
+	}
+
+	buf.Reset()
+	if err := ExportSeries(&buf, testSeries, JSON); err != nil {
+		t.Fatalf("Failed to export series: %v", err)
+	}
+	var series []graphql.Series
+	if err := json.Unmarshal(buf.Bytes(), &series); err != nil || len(series) != 1 || series[0].ID != "2620066" {
+		t.Fatalf("Unexpected JSON export %s (%v)", buf.String(), err)
+	}
+}
+
+func TestExportTableJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ExportTable(&buf, []string{"B", "A"}, [][]string{{"2", "1"}}, NDJSON); err != nil {
+		t.Fatalf("Failed to export table: %v", err)
+	}
+	if expected := `{"B":"2","A":"1"}` + "\n"; buf.String() != expected {
+		t.Fatalf("Expected %q, but got %q", expected, buf.String())
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	headers := []string{"Start Time", "Serie ID", "Tournament", "Kills"}
+	rows := [][]string{
+		{"2024-05-10T12:00:00Z", "2620066", "Liga Açaí", "12"},
+		{"2024-05-11T12:00:00Z", "2620067", "LEC", "7"},
+		{"2024-05-12T12:00:00Z", "2620068", "Liga Açaí", "9"},
+	}
+	sheets, err := SplitSheets(headers, rows, "tournament")
+	if err != nil {
+		t.Fatalf("Failed to split sheets: %v", err)
+	}
+	if len(sheets) != 2 || len(sheets[0].Rows) != 2 || sheets[1].Name != "LEC" {
+		t.Fatalf("Unexpected sheets %+v", sheets)
+	}
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skipf("Timezone database unavailable: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, sheets, XLSXOptions{Location: paris}); err != nil {
+		t.Fatalf("Failed to write XLSX: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Failed to read workbook: %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+
+	if !strings.Contains(files["xl/workbook.xml"], `name="Liga Açaí"`) {
+		t.Fatalf("Expected a sheet per tournament, but got %s", files["xl/workbook.xml"])
+	}
+	sheet := files["xl/worksheets/sheet1.xml"]
+	// 2024-05-10 14:00 in Paris.
+	for _, expected := range []string{`state="frozen"`, `<c r="A2" s="2"><v>45422.583333333336</v></c>`, `<c r="D2"><v>12</v></c>`, `<t xml:space="preserve">2620066</t>`, `<t xml:space="preserve">Liga Açaí</t>`, `<col min="3" max="3" width="12"`} {
+		if !strings.Contains(sheet, expected) {
+			t.Fatalf("Expected %s in sheet, but got %s", expected, sheet)
+		}
+	}
+}
+
+func TestWriteICS(t *testing.T) {
+	series := append(testSeries, graphql.Series{
+		ID:                 "2620067",
+		StartTimeScheduled: "2024-05-10T15:00:00Z",
+		Tournament:         graphql.Tournament{Name: "LEC Spring, 2024; Playoffs"},
+		Format:             graphql.Format{NameShortened: "Bo5"},
+	})
+	var buf bytes.Buffer
+	stamp := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	if err := WriteICS(&buf, series, ICSOptions{Stamp: stamp}); err != nil {
+		t.Fatalf("Failed to write calendar: %v", err)
+	}
+	ics := buf.String()
+	for _, expected := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:series-2620066@stealth-grid-cli\r\n",
+		"DTSTART:20240510T110000Z\r\nDTEND:20240510T140000Z\r\n",
+		"SUMMARY:Team 1 vs Team 2 (LEC Spring 2024)\r\n",
+		"DTSTART:20240510T150000Z\r\nDTEND:20240510T200000Z\r\n",
+		`SUMMARY:TBD (LEC Spring\, 2024\; Playoffs)`,
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(ics, expected) {
+			t.Fatalf("Expected %q in calendar, but got %s", expected, ics)
+		}
+	}
+	for _, line := range strings.Split(ics, "\r\n") {
+		if len(line) > 75 {
+			t.Fatalf("Expected lines to be folded at 75 octets, but got %q", line)
+		}
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	series := []graphql.Series{
+		{ID: "3", StartTimeScheduled: "2024-05-11T09:00:00Z", Tournament: graphql.Tournament{Name: "LEC"}},
+		{ID: "2", StartTimeScheduled: "2024-05-10T18:00:00Z", Tournament: graphql.Tournament{Name: "LCK"},
+			Teams: []graphql.SeriesTeam{{BaseInfo: graphql.TeamInfo{Name: "T1"}}, {BaseInfo: graphql.TeamInfo{Name: "Gen<G>"}}}},
+		testSeries[0],
+	}
+
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, series, Markdown, ReportOptions{Location: time.UTC}); err != nil {
+		t.Fatalf("Failed to write Markdown report: %v", err)
+	}
+	md := buf.String()
+	friday := strings.Index(md, "## Friday, 10 May 2024")
+	saturday := strings.Index(md, "## Saturday, 11 May 2024")
+	if friday < 0 || saturday < friday {
+		t.Fatalf("Expected the report to be grouped by day, but got %s", md)
+	}
+	if lec, lck := strings.Index(md, "### LEC Spring 2024"), strings.Index(md, "### LCK"); lec < friday || lck < lec || lck > saturday {
+		t.Fatalf("Expected the tournaments of a day in order, but got %s", md)
+	}
+	if !strings.Contains(md, `| 18:00 | T1 vs Gen\<G\> |  | 2 |`) {
+		t.Fatalf("Expected an escaped series row, but got %s", md)
+	}
+
+	buf.Reset()
+	if err := WriteReport(&buf, series, HTML, ReportOptions{Location: time.UTC, Title: "Week 19"}); err != nil {
+		t.Fatalf("Failed to write HTML report: %v", err)
+	}
+	if html := buf.String(); !strings.Contains(html, "<h1>Week 19</h1>") || !strings.Contains(html, "T1 vs Gen&lt;G&gt;") {
+		t.Fatalf("Unexpected HTML report %s", html)
+	}
+
+	buf.Reset()
+	custom := `{{range .Days}}{{range .Tournaments}}{{.Name}}:{{len .Series}};{{end}}{{end}}`
+	if err := WriteReport(&buf, series, Markdown, ReportOptions{Location: time.UTC, Template: custom}); err != nil {
+		t.Fatalf("Failed to write report with a custom template: %v", err)
+	}
+	if expected := "LEC Spring 2024:1;LCK:1;LEC:1;"; buf.String() != expected {
+		t.Fatalf("Expected %q, but got %q", expected, buf.String())
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	columns, err := ParseColumns([]string{"format", "Series_ID", "team_two_id", "title"})
+	if err != nil {
+		t.Fatalf("Failed to parse columns: %v", err)
+	}
+	series := testSeries[0]
+	series.Title = graphql.Title{ID: "3", Name: "League of Legends", NameShortened: "LoL"}
+	if row := strings.Join(ColumnRow(series, columns), ","); row != "Bo3,2620066,t2,LoL" {
+		t.Fatalf("Expected row %q, but got %q", "Bo3,2620066,t2,LoL", row)
+	}
+	if headers := strings.Join(Headers(DefaultColumns()), ","); headers != strings.Join(SeriesHeaders, ",") {
+		t.Fatalf("Expected the default headers %v, but got %s", SeriesHeaders, headers)
+	}
+	results, err := ParseColumns([]string{"score", "winner"})
+	if err != nil || !NeedsResults(results) || NeedsResults(columns) {
+		t.Fatalf("Expected the result columns to need results, but got %v", err)
+	}
+	if row := strings.Join(ColumnRow(series, results), ","); row != "," {
+		t.Fatalf("Expected empty cells without a result, but got %q", row)
+	}
+	series.Result = &graphql.Result{Scores: map[string]int{"t1": 2, "t2": 1}, Winner: "Team 1"}
+	if row := strings.Join(ColumnRow(series, results), ","); row != "2-1,Team 1" {
+		t.Fatalf("Expected row %q, but got %q", "2-1,Team 1", row)
+	}
+	for _, keys := range [][]string{{"unknown"}, {"format", "format"}} {
+		if _, err := ParseColumns(keys); err == nil {
+			t.Fatalf("Expected an error for columns %v", keys)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := ExportSeriesColumns(&buf, testSeries, columns[:2], CSV); err != nil {
+		t.Fatalf("Failed to export series: %v", err)
+	}
+	if expected := "Format,Serie ID\nBo3,2620066\n"; buf.String() != expected {
+		t.Fatalf("Expected %q, but got %q", expected, buf.String())
+	}
+}
+
+func TestUpsertSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "series.sqlite")
+	first := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	result, err := UpsertSQLite(path, testSeries, SQLiteOptions{Now: first})
+	if err != nil || result.Inserted != 1 {
+		t.Fatalf("Expected 1 inserted series, but got %+v (%v)", result, err)
+	}
+
+	rescheduled := testSeries[0]
+	rescheduled.StartTimeScheduled = "2024-05-10T13:00:00Z"
+	other := testSeries[0]
+	other.ID = "2620067"
+	second := first.Add(24 * time.Hour)
+	result, err = UpsertSQLite(path, []graphql.Series{rescheduled, other}, SQLiteOptions{Now: second})
+	if err != nil {
+		t.Fatalf("Failed to upsert series: %v", err)
+	}
+	if result != (SQLiteResult{Inserted: 1, Updated: 1, Rescheduled: 1}) {
+		t.Fatalf("Unexpected result %+v", result)
+	}
+	if result, err = UpsertSQLite(path, []graphql.Series{other}, SQLiteOptions{Now: second.Add(time.Hour)}); err != nil || result.Unchanged != 1 {
+		t.Fatalf("Expected 1 unchanged series, but got %+v (%v)", result, err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	var start, firstSeen, lastUpdated, previous string
+	err = db.QueryRow(`SELECT s.start_time_scheduled, s.first_seen, s.last_updated, c.previous_start_time
+		FROM series s JOIN schedule_changes c ON c.series_id = s.id WHERE s.id = '2620066'`).Scan(&start, &firstSeen, &lastUpdated, &previous)
+	if err != nil {
+		t.Fatalf("Failed to query database: %v", err)
+	}
+	if start != "2024-05-10T13:00:00Z" || firstSeen != "2024-05-01T00:00:00Z" || lastUpdated != "2024-05-02T00:00:00Z" || previous != "2024-05-10T11:00:00Z" {
+		t.Fatalf("Unexpected series %s %s %s %s", start, firstSeen, lastUpdated, previous)
+	}
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM series`).Scan(&count); err != nil || count != 2 {
+		t.Fatalf("Expected 2 series, but got %d (%v)", count, err)
+	}
+}
+
+func TestNewSeriesWriter(t *testing.T) {
+	for _, format := range []Format{JSON, NDJSON, CSV} {
+		var streamed, exported bytes.Buffer
+		w, err := NewSeriesWriter(&streamed, format, StreamOptions{})
+		if err != nil {
+			t.Fatalf("Failed to create %s writer: %v", format, err)
+		}
+		for _, s := range append(testSeries, testSeries...) {
+			if err := w.Write(s); err != nil {
+				t.Fatalf("Failed to write series: %v", err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Failed to flush series: %v", err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Failed to close writer: %v", err)
+		}
+		if err := ExportSeries(&exported, append(testSeries, testSeries...), format); err != nil {
+			t.Fatalf("Failed to export series: %v", err)
+		}
+		if streamed.String() != exported.String() {
+			t.Fatalf("Expected the %s stream to match the export %q, but got %q", format, exported.String(), streamed.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	w, _ := NewSeriesWriter(&buf, JSON, StreamOptions{})
+	if err := w.Close(); err != nil || buf.String() != "[]\n" {
+		t.Fatalf("Expected an empty JSON array, but got %q (%v)", buf.String(), err)
 	}
-	ExportData(rows)
-	if _, err := os.Stat("games.csv"); os.IsNotExist(err) {
-		t.Fatalf("Expected file games.csv to be created, but it does not exist")
+	if _, err := NewSeriesWriter(&buf, SQLite, StreamOptions{}); err == nil {
+		t.Fatalf("Expected an error for a SQLite stream")
 	}
 }
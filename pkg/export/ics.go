@@ -0,0 +1,157 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/graphql"
+)
+
+// ICS exports an iCalendar file with one event per series.
+const ICS Format = "ics"
+
+const (
+	// icsLineLength is the maximum length of a line of an iCalendar file, in octets.
+	icsLineLength = 75
+
+	// icsTimeLayout is the layout of UTC times in iCalendar files.
+	icsTimeLayout = "20060102T150405Z"
+
+	// icsUIDDomain makes the UIDs of the events globally unique.
+	icsUIDDomain = "stealth-grid-cli"
+)
+
+// ICSOptions configures the calendars written by WriteICS.
+type ICSOptions struct {
+	GameDuration time.Duration // GameDuration is the estimated duration of a game, one hour if zero.
+	Stamp        time.Time     // Stamp is the time the calendar is created at, now if zero.
+}
+
+// UID returns the UID of the event of a series, derived from its ID only so that importing a
+// calendar again updates its events instead of duplicating them.
+func UID(seriesID string) string {
+	return "series-" + seriesID + "@" + icsUIDDomain
+}
+
+// EstimatedDuration returns the estimated duration of a series from its format: a best of
+// N series lasts at most N games, e.g. three for "Bo3". Series of unknown format last one game.
+func EstimatedDuration(format string, gameDuration time.Duration) time.Duration {
+	if gameDuration <= 0 {
+		gameDuration = time.Hour
+	}
+	games := graphql.Format{NameShortened: format}.BestOf()
+	if games == 0 {
+		games = 1
+	}
+	return time.Duration(games) * gameDuration
+}
+
+// WriteICS writes series as the events of an iCalendar file.
+//
+// Each series starts at its scheduled start time and lasts the estimated duration of its
+// format. Its summary names the teams and the tournament. Series without a valid start time
+// are skipped.
+//
+// Parameters:
+//   - w: The destination of the calendar.
+//   - series: The series to write.
+//   - opts: The duration of a game and the creation time of the calendar.
+//
+// Returns:
+//   - error: An error if the calendar cannot be written.
+func WriteICS(w io.Writer, series []graphql.Series, opts ICSOptions) error {
+	stamp := opts.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeICSLine(bw, name+":"+value)
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//Stealth Grid CLI//Series Schedule//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	for _, s := range series {
+		start, err := time.Parse(time.RFC3339, s.StartTimeScheduled)
+		if err != nil {
+			continue
+		}
+		end := start.Add(EstimatedDuration(s.Format.NameShortened, opts.GameDuration))
+
+		teams := strings.Join(s.TeamNames(), " vs ")
+		if teams == "" {
+			teams = "TBD"
+		}
+		summary := teams
+		if s.Tournament.Name != "" {
+			summary += " (" + s.Tournament.Name + ")"
+		}
+		description := "Series " + s.ID
+		if s.Format.NameShortened != "" {
+			description += ", " + s.Format.NameShortened
+		}
+
+		line("BEGIN", "VEVENT")
+		line("UID", UID(s.ID))
+		line("DTSTAMP", stamp.UTC().Format(icsTimeLayout))
+		line("DTSTART", start.UTC().Format(icsTimeLayout))
+		line("DTEND", end.UTC().Format(icsTimeLayout))
+		line("SUMMARY", escapeICS(summary))
+		line("DESCRIPTION", escapeICS(description))
+		if s.Tournament.Name != "" {
+			line("CATEGORIES", escapeICS(s.Tournament.Name))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("error writing iCalendar: %v", err)
+	}
+	return nil
+}
+
+// escapeICS escapes a text value of an iCalendar file.
+func escapeICS(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// writeICSLine writes a content line, folded every 75 octets without splitting characters.
+func writeICSLine(w *bufio.Writer, s string) {
+	length := 0
+	for _, r := range s {
+		size := len(string(r))
+		if length+size > icsLineLength {
+			w.WriteString("\r\n ")
+			length = 1
+		}
+		w.WriteRune(r)
+		length += size
+	}
+	w.WriteString("\r\n")
+}
+
+// seriesFromRows rebuilds series from the rows of the series table, matching SeriesHeaders.
+func seriesFromRows(rows [][]string) []graphql.Series {
+	series := make([]graphql.Series, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < len(SeriesHeaders) {
+			continue
+		}
+		series = append(series, graphql.Series{
+			StartTimeScheduled: row[0],
+			ID:                 row[1],
+			Tournament:         graphql.Tournament{Name: row[2]},
+			Teams: []graphql.SeriesTeam{
+				{BaseInfo: graphql.TeamInfo{Name: row[3]}},
+				{BaseInfo: graphql.TeamInfo{Name: row[4]}},
+			},
+		})
+	}
+	return series
+}
@@ -0,0 +1,121 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/graphql"
+)
+
+// rowObject is a row of a table encoded as a JSON object keyed by the headers of the table,
+// in the order of the columns.
+type rowObject struct {
+	headers []string
+	values  []string
+}
+
+// MarshalJSON encodes the row as a JSON object.
+func (r rowObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, h := range r.headers {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(h)
+		if err != nil {
+			return nil, err
+		}
+		value := ""
+		if i < len(r.values) {
+			value = r.values[i]
+		}
+		val, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// ExportSeries writes series with all their fields.
+//
+// In CSV and XLSX, the series are written as the rows of the series table in the default
+// columns. In JSON and NDJSON, they are written as the objects returned by the GRID API, with
+// the IDs and the format of the series, its title, its tournament and its teams. In ICS, they
+// are written as the events of a calendar, and in Markdown and HTML as a schedule report with
+// the default template.
+//
+// Parameters:
+//   - w: The destination of the export.
+//   - series: The series to export.
+//   - format: The format of the export.
+//
+// Returns:
+//   - error: An error if the format is not supported or the series cannot be written.
+func ExportSeries(w io.Writer, series []graphql.Series, format Format) error {
+	return ExportSeriesColumns(w, series, DefaultColumns(), format)
+}
+
+// ExportSeriesColumns writes series like ExportSeries, with the given columns in CSV and XLSX.
+//
+// Parameters:
+//   - w: The destination of the export.
+//   - series: The series to export.
+//   - columns: The columns of the rows of the series in CSV and XLSX.
+//   - format: The format of the export.
+//
+// Returns:
+//   - error: An error if the format is not supported or the series cannot be written.
+func ExportSeriesColumns(w io.Writer, series []graphql.Series, columns []Column, format Format) error {
+	switch format {
+	case JSON, NDJSON:
+		if series == nil {
+			series = []graphql.Series{}
+		}
+		return writeJSON(w, series, format)
+	case ICS:
+		return WriteICS(w, series, ICSOptions{})
+	case Markdown, HTML:
+		return WriteReport(w, series, format, ReportOptions{})
+	}
+	return ExportTable(w, Headers(columns), ColumnRows(series, columns), format)
+}
+
+// SeriesRow returns the row of a series in the default columns of the series table, matching SeriesHeaders.
+func SeriesRow(s graphql.Series) []string {
+	return ColumnRow(s, DefaultColumns())
+}
+
+// writeJSON writes values as an indented JSON array, or as one JSON document per line.
+func writeJSON[T any](w io.Writer, values []T, format Format) error {
+	if format == JSON {
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(values); err != nil {
+			return fmt.Errorf("error writing JSON: %v", err)
+		}
+		return nil
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	for _, v := range values {
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("error writing JSON: %v", err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("error writing JSON: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package export
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/graphql"
+)
+
+const (
+	// Markdown exports a schedule report as a Markdown document.
+	Markdown Format = "md"
+
+	// HTML exports a schedule report as a self-contained HTML page.
+	HTML Format = "html"
+)
+
+// Report is the data a schedule report template is rendered with.
+type Report struct {
+	Title     string      // Title is the title of the report.
+	Generated time.Time   // Generated is the time the report was generated, in its timezone.
+	Timezone  string      // Timezone is the name of the timezone of the times of the report.
+	Days      []ReportDay // Days are the days of the schedule, in order.
+}
+
+// ReportDay is a day of a schedule report.
+type ReportDay struct {
+	Date        time.Time          // Date is the start of the day, in the timezone of the report.
+	Tournaments []ReportTournament // Tournaments are the tournaments playing on the day, in order of their first series.
+}
+
+// ReportTournament is a tournament playing on a day of a schedule report.
+type ReportTournament struct {
+	Name   string         // Name is the name of the tournament.
+	Series []ReportSeries // Series are the series of the tournament on the day, in order.
+}
+
+// ReportSeries is a series of a schedule report.
+type ReportSeries struct {
+	graphql.Series
+	Start time.Time // Start is the scheduled start time of the series, in the timezone of the report.
+}
+
+// ReportOptions configures the reports written by WriteReport.
+type ReportOptions struct {
+	Title    string         // Title is the title of the report, "Series schedule" if empty.
+	Location *time.Location // Location is the timezone of the times of the report, time.Local if nil.
+	Template string         // Template replaces the default template of the format, if set.
+}
+
+// reportFuncs are the functions available to report templates, in addition to the builtins.
+var reportFuncs = map[string]any{
+	"join": strings.Join,
+	"md":   escapeMarkdown,
+}
+
+// DefaultMarkdownTemplate is the default text/template of Markdown reports.
+const DefaultMarkdownTemplate = `# {{.Title}}
+{{range .Days}}
+## {{.Date.Format "Monday, 2 January 2006"}}
+{{range .Tournaments}}
+### {{md .Name}}
+
+| Time | Match | Format | Series |
+| --- | --- | --- | --- |
+{{range .Series}}| {{.Start.Format "15:04"}} | {{md (join .TeamNames " vs ")}} | {{.Format.NameShortened}} | {{.ID}} |
+{{end}}{{end}}{{end}}
+_Times in {{.Timezone}}. Generated on {{.Generated.Format "2006-01-02 15:04"}}._
+`
+
+// DefaultHTMLTemplate is the default html/template of HTML reports.
+const DefaultHTMLTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem auto; max-width: 60rem; padding: 0 1rem; color: #222; }
+h2 { border-bottom: 2px solid #5f5fd7; padding-bottom: .25rem; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1rem; }
+th, td { text-align: left; padding: .35rem .6rem; border-bottom: 1px solid #ddd; }
+th { background: #f3f3fb; }
+td.time { width: 5rem; font-variant-numeric: tabular-nums; }
+footer { color: #777; font-size: .85rem; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Days}}<section>
+<h2>{{.Date.Format "Monday, 2 January 2006"}}</h2>
+{{range .Tournaments}}<h3>{{.Name}}</h3>
+<table>
+<thead><tr><th>Time</th><th>Match</th><th>Format</th><th>Series</th></tr></thead>
+<tbody>
+{{range .Series}}<tr><td class="time">{{.Start.Format "15:04"}}</td><td>{{join .TeamNames " vs "}}</td><td>{{.Format.NameShortened}}</td><td>{{.ID}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}</section>
+{{end}}<footer>Times in {{.Timezone}}. Generated on {{.Generated.Format "2006-01-02 15:04"}}.</footer>
+</body>
+</html>
+`
+
+// TemplateName returns the name of the report template of a format in the configuration,
+// e.g. "markdown" for the "report.markdown_template" key.
+func TemplateName(format Format) string {
+	if format == Markdown {
+		return "markdown"
+	}
+	return string(format)
+}
+
+// ReadTemplate reads a custom report template.
+//
+// Parameters:
+//   - path: The path of the template, or an empty string for the default template.
+//
+// Returns:
+//   - string: The content of the template, empty for the default template.
+//   - error: An error if the template cannot be read.
+func ReadTemplate(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading report template: %v", err)
+	}
+	return string(data), nil
+}
+
+// BuildReport groups series by day and tournament.
+//
+// Series are sorted by start time and grouped by the day they start on in the timezone of the
+// options. Series without a valid start time are left out.
+//
+// Parameters:
+//   - series: The series of the report.
+//   - opts: The title and the timezone of the report.
+//
+// Returns:
+//   - Report: The data of the report.
+func BuildReport(series []graphql.Series, opts ReportOptions) Report {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	r := Report{Title: opts.Title, Generated: time.Now().In(loc), Timezone: loc.String()}
+	if r.Title == "" {
+		r.Title = "Series schedule"
+	}
+
+	var scheduled []ReportSeries
+	for _, s := range series {
+		start, err := time.Parse(time.RFC3339, s.StartTimeScheduled)
+		if err != nil {
+			continue
+		}
+		scheduled = append(scheduled, ReportSeries{Series: s, Start: start.In(loc)})
+	}
+	sort.SliceStable(scheduled, func(i, j int) bool {
+		return scheduled[i].Start.Before(scheduled[j].Start)
+	})
+
+	for _, s := range scheduled {
+		y, m, d := s.Start.Date()
+		date := time.Date(y, m, d, 0, 0, 0, 0, loc)
+		if len(r.Days) == 0 || !r.Days[len(r.Days)-1].Date.Equal(date) {
+			r.Days = append(r.Days, ReportDay{Date: date})
+		}
+		day := &r.Days[len(r.Days)-1]
+
+		index := -1
+		for i, t := range day.Tournaments {
+			if t.Name == s.Tournament.Name {
+				index = i
+			}
+		}
+		if index < 0 {
+			index = len(day.Tournaments)
+			day.Tournaments = append(day.Tournaments, ReportTournament{Name: s.Tournament.Name})
+		}
+		day.Tournaments[index].Series = append(day.Tournaments[index].Series, s)
+	}
+	return r
+}
+
+// WriteReport renders a schedule report of series as Markdown or HTML.
+//
+// Templates are rendered with a Report. Besides the builtins of text/template, they can call
+// "join" (strings.Join) and "md", which escapes text for Markdown. HTML templates are parsed
+// with html/template, so that the names of teams and tournaments are escaped.
+//
+// Parameters:
+//   - w: The destination of the report.
+//   - series: The series of the report.
+//   - format: Either Markdown or HTML.
+//   - opts: The title, the timezone and the template of the report.
+//
+// Returns:
+//   - error: An error if the format is not a report format, the template is invalid or the report cannot be written.
+func WriteReport(w io.Writer, series []graphql.Series, format Format, opts ReportOptions) error {
+	report := BuildReport(series, opts)
+	switch format {
+	case Markdown:
+		source := opts.Template
+		if source == "" {
+			source = DefaultMarkdownTemplate
+		}
+		tmpl, err := texttemplate.New("report").Funcs(reportFuncs).Parse(source)
+		if err != nil {
+			return fmt.Errorf("error parsing report template: %v", err)
+		}
+		if err := tmpl.Execute(w, report); err != nil {
+			return fmt.Errorf("error writing report: %v", err)
+		}
+		return nil
+	case HTML:
+		source := opts.Template
+		if source == "" {
+			source = DefaultHTMLTemplate
+		}
+		tmpl, err := htmltemplate.New("report").Funcs(reportFuncs).Parse(source)
+		if err != nil {
+			return fmt.Errorf("error parsing report template: %v", err)
+		}
+		if err := tmpl.Execute(w, report); err != nil {
+			return fmt.Errorf("error writing report: %v", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s is not a report format", format)
+}
+
+// escapeMarkdown escapes the characters of a text that Markdown would interpret, including the
+// pipes of tables.
+func escapeMarkdown(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("\\`*_{}[]<>#|", r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,167 @@
+package export
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/graphql"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// SQLite exports series to a SQLite database file, updating the series it already holds.
+const SQLite Format = "sqlite"
+
+// sqliteSchema creates the tables of a SQLite export.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS series (
+	id                   TEXT PRIMARY KEY,
+	title_id             TEXT NOT NULL DEFAULT '',
+	title                TEXT NOT NULL DEFAULT '',
+	tournament_id        TEXT NOT NULL DEFAULT '',
+	tournament           TEXT NOT NULL DEFAULT '',
+	tournament_short     TEXT NOT NULL DEFAULT '',
+	format               TEXT NOT NULL DEFAULT '',
+	start_time_scheduled TEXT NOT NULL DEFAULT '',
+	team_one_id          TEXT NOT NULL DEFAULT '',
+	team_one             TEXT NOT NULL DEFAULT '',
+	team_two_id          TEXT NOT NULL DEFAULT '',
+	team_two             TEXT NOT NULL DEFAULT '',
+	first_seen           TEXT NOT NULL,
+	last_updated         TEXT NOT NULL,
+	last_seen            TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS schedule_changes (
+	series_id           TEXT NOT NULL REFERENCES series(id),
+	changed_at          TEXT NOT NULL,
+	previous_start_time TEXT NOT NULL,
+	start_time          TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS schedule_changes_series ON schedule_changes (series_id, changed_at);
+`
+
+// SQLiteOptions configures the exports of UpsertSQLite.
+type SQLiteOptions struct {
+	Now time.Time // Now is the time of the export recorded in the database, now if zero.
+}
+
+// SQLiteResult counts the series of an export to SQLite by outcome.
+type SQLiteResult struct {
+	Inserted    int // Inserted is the number of series that were not in the database yet.
+	Updated     int // Updated is the number of series with at least one changed field.
+	Rescheduled int // Rescheduled is the number of updated series whose start time changed.
+	Unchanged   int // Unchanged is the number of series identical to the database.
+}
+
+// UpsertSQLite exports series to a SQLite database, so that a daily export keeps a single
+// up-to-date table instead of a CSV file per day.
+//
+// Series are upserted by ID in the "series" table, with the time they were first exported
+// (first_seen), last changed (last_updated) and last exported (last_seen). Each change of the
+// scheduled start time of a series is recorded in the "schedule_changes" table. The database
+// and its tables are created if needed.
+//
+// Parameters:
+//   - path: The path of the SQLite database file.
+//   - series: The series to export.
+//   - opts: The time of the export.
+//
+// Returns:
+//   - SQLiteResult: The number of inserted, updated, rescheduled and unchanged series.
+//   - error: An error if the database cannot be opened or written.
+func UpsertSQLite(path string, series []graphql.Series, opts SQLiteOptions) (SQLiteResult, error) {
+	var result SQLiteResult
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	stamp := now.UTC().Format(time.RFC3339)
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return result, fmt.Errorf("error opening database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return result, fmt.Errorf("error creating database tables: %v", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return result, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, s := range series {
+		values := sqliteValues(s)
+		args := make([]any, len(values))
+		for i, v := range values {
+			args[i] = v
+		}
+		previous := make([]string, len(values))
+		pointers := make([]any, len(previous))
+		for i := range previous {
+			pointers[i] = &previous[i]
+		}
+		err := tx.QueryRow(`SELECT title_id, title, tournament_id, tournament, tournament_short, format,
+			start_time_scheduled, team_one_id, team_one, team_two_id, team_two FROM series WHERE id = ?`, s.ID).Scan(pointers...)
+		if err == sql.ErrNoRows {
+			_, err = tx.Exec(`INSERT INTO series (id, title_id, title, tournament_id, tournament, tournament_short, format,
+				start_time_scheduled, team_one_id, team_one, team_two_id, team_two, first_seen, last_updated, last_seen)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				append(append([]any{s.ID}, args...), stamp, stamp, stamp)...)
+			if err != nil {
+				return result, fmt.Errorf("error inserting series %s: %v", s.ID, err)
+			}
+			result.Inserted++
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("error reading series %s: %v", s.ID, err)
+		}
+
+		changed := false
+		for i, v := range values {
+			if previous[i] != v {
+				changed = true
+			}
+		}
+		if !changed {
+			if _, err := tx.Exec(`UPDATE series SET last_seen = ? WHERE id = ?`, stamp, s.ID); err != nil {
+				return result, fmt.Errorf("error updating series %s: %v", s.ID, err)
+			}
+			result.Unchanged++
+			continue
+		}
+
+		_, err = tx.Exec(`UPDATE series SET title_id = ?, title = ?, tournament_id = ?, tournament = ?, tournament_short = ?, format = ?,
+			start_time_scheduled = ?, team_one_id = ?, team_one = ?, team_two_id = ?, team_two = ?, last_updated = ?, last_seen = ?
+			WHERE id = ?`, append(args, stamp, stamp, s.ID)...)
+		if err != nil {
+			return result, fmt.Errorf("error updating series %s: %v", s.ID, err)
+		}
+		result.Updated++
+
+		if start := previous[6]; start != s.StartTimeScheduled {
+			_, err := tx.Exec(`INSERT INTO schedule_changes (series_id, changed_at, previous_start_time, start_time) VALUES (?, ?, ?, ?)`,
+				s.ID, stamp, start, s.StartTimeScheduled)
+			if err != nil {
+				return result, fmt.Errorf("error recording schedule change of series %s: %v", s.ID, err)
+			}
+			result.Rescheduled++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("error committing export: %v", err)
+	}
+	return result, nil
+}
+
+// sqliteValues returns the fields of a series stored in the series table of a SQLite export,
+// from title_id to team_two.
+func sqliteValues(s graphql.Series) []string {
+	one, two := teamInfo(s, 0), teamInfo(s, 1)
+	return []string{s.Title.ID, s.Title.NameShortened, s.Tournament.ID, s.Tournament.Name, s.Tournament.NameShortened,
+		s.Format.NameShortened, s.StartTimeScheduled, one.ID, one.Name, two.ID, two.Name}
+}
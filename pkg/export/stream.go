@@ -0,0 +1,171 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/graphql"
+)
+
+// SeriesWriter writes series one at a time, e.g. as the pages of a query arrive.
+type SeriesWriter interface {
+	// Write writes a series. It may be buffered until the next call to Flush.
+	Write(s graphql.Series) error
+
+	// Flush writes the buffered series to the underlying writer.
+	Flush() error
+
+	// Close ends the export, e.g. by closing the JSON array, and flushes it.
+	Close() error
+}
+
+// StreamOptions configures the series writers created by NewSeriesWriter.
+type StreamOptions struct {
+	Columns []Column      // Columns are the columns of CSV and XLSX exports, the default columns if empty.
+	XLSX    XLSXOptions   // XLSX configures XLSX exports.
+	ICS     ICSOptions    // ICS configures ICS exports.
+	Report  ReportOptions // Report configures Markdown and HTML exports.
+}
+
+// NewSeriesWriter creates a writer exporting series to w in a format.
+//
+// CSV, JSON and NDJSON exports are streamed: each series is written as soon as it is flushed.
+// XLSX, ICS, Markdown and HTML exports are written when the writer is closed, since they sort
+// or group all the series. SQLite exports are written to a database file with UpsertSQLite.
+//
+// Parameters:
+//   - w: The destination of the export.
+//   - format: The format of the export.
+//   - opts: The columns and the options of the formats.
+//
+// Returns:
+//   - SeriesWriter: The writer of the series. It must be closed to complete the export.
+//   - error: An error if the format cannot be written to w or the header of the export cannot be written.
+func NewSeriesWriter(w io.Writer, format Format, opts StreamOptions) (SeriesWriter, error) {
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = DefaultColumns()
+	}
+	switch format {
+	case CSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(Headers(columns)); err != nil {
+			return nil, fmt.Errorf("error writing headers to CSV: %v", err)
+		}
+		return &csvSeriesWriter{writer: writer, columns: columns}, nil
+	case JSON, NDJSON:
+		return &jsonSeriesWriter{w: bufio.NewWriter(w), array: format == JSON}, nil
+	case XLSX, ICS, Markdown, HTML:
+		return &bufferedSeriesWriter{w: w, format: format, columns: columns, opts: opts}, nil
+	case SQLite:
+		return nil, fmt.Errorf("%s exports are written to a database file with UpsertSQLite", format)
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+// csvSeriesWriter streams series as the rows of a CSV table.
+type csvSeriesWriter struct {
+	writer  *csv.Writer
+	columns []Column
+}
+
+func (c *csvSeriesWriter) Write(s graphql.Series) error {
+	if err := c.writer.Write(ColumnRow(s, c.columns)); err != nil {
+		return fmt.Errorf("error writing record to CSV: %v", err)
+	}
+	return nil
+}
+
+func (c *csvSeriesWriter) Flush() error {
+	c.writer.Flush()
+	if err := c.writer.Error(); err != nil {
+		return fmt.Errorf("error writing CSV: %v", err)
+	}
+	return nil
+}
+
+func (c *csvSeriesWriter) Close() error {
+	return c.Flush()
+}
+
+// jsonSeriesWriter streams series as an indented JSON array, like ExportSeries, or as NDJSON.
+type jsonSeriesWriter struct {
+	w     *bufio.Writer
+	array bool
+	count int
+}
+
+func (j *jsonSeriesWriter) Write(s graphql.Series) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if j.array {
+		enc.SetIndent("  ", "  ")
+	}
+	if err := enc.Encode(s); err != nil {
+		return fmt.Errorf("error writing JSON: %v", err)
+	}
+	if j.array {
+		if j.count == 0 {
+			j.w.WriteString("[\n  ")
+		} else {
+			j.w.WriteString(",\n  ")
+		}
+		j.w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	} else {
+		j.w.Write(buf.Bytes())
+	}
+	j.count++
+	return nil
+}
+
+func (j *jsonSeriesWriter) Flush() error {
+	if err := j.w.Flush(); err != nil {
+		return fmt.Errorf("error writing JSON: %v", err)
+	}
+	return nil
+}
+
+func (j *jsonSeriesWriter) Close() error {
+	if j.array {
+		if j.count == 0 {
+			j.w.WriteString("[]\n")
+		} else {
+			j.w.WriteString("\n]\n")
+		}
+	}
+	return j.Flush()
+}
+
+// bufferedSeriesWriter collects series and exports them all when closed.
+type bufferedSeriesWriter struct {
+	w       io.Writer
+	format  Format
+	columns []Column
+	opts    StreamOptions
+	series  []graphql.Series
+}
+
+func (b *bufferedSeriesWriter) Write(s graphql.Series) error {
+	b.series = append(b.series, s)
+	return nil
+}
+
+func (b *bufferedSeriesWriter) Flush() error {
+	return nil
+}
+
+func (b *bufferedSeriesWriter) Close() error {
+	switch b.format {
+	case XLSX:
+		sheet := Sheet{Name: "Series", Headers: Headers(b.columns), Rows: ColumnRows(b.series, b.columns)}
+		return WriteXLSX(b.w, []Sheet{sheet}, b.opts.XLSX)
+	case ICS:
+		return WriteICS(b.w, b.series, b.opts.ICS)
+	}
+	return WriteReport(b.w, b.series, b.format, b.opts.Report)
+}
@@ -0,0 +1,316 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// XLSX exports an Excel workbook.
+const XLSX Format = "xlsx"
+
+const (
+	// maxSheetName is the maximum length of the name of a worksheet.
+	maxSheetName = 31
+
+	// maxColumnWidth bounds the automatic width of a column, in characters.
+	maxColumnWidth = 60
+
+	// dateLayout is how dates are displayed in workbooks.
+	dateLayout = "yyyy-mm-dd hh:mm"
+)
+
+// excelEpoch is the origin of the serial dates of Excel.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// Sheet is a worksheet of a workbook.
+type Sheet struct {
+	Name    string     // Name is the name of the worksheet, shortened and made unique when written.
+	Headers []string   // Headers are the headers of the table of the worksheet.
+	Rows    [][]string // Rows are the rows of the table of the worksheet.
+}
+
+// XLSXOptions configures the workbooks written by WriteXLSX.
+type XLSXOptions struct {
+	Location *time.Location // Location is the timezone dates are displayed in, time.Local if nil.
+}
+
+// SplitSheets splits a table into one worksheet per value of a column, e.g. one per tournament,
+// in the order the values first appear.
+//
+// Parameters:
+//   - headers: The headers of the table.
+//   - rows: The rows of the table.
+//   - column: The header of the column the rows are split by.
+//
+// Returns:
+//   - []Sheet: The worksheets, named after the values of the column.
+//   - error: An error if the table has no such column.
+func SplitSheets(headers []string, rows [][]string, column string) ([]Sheet, error) {
+	index := -1
+	for i, h := range headers {
+		if strings.EqualFold(h, column) {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("no column %q to split the workbook by", column)
+	}
+
+	var sheets []Sheet
+	positions := map[string]int{}
+	for _, row := range rows {
+		value := ""
+		if index < len(row) {
+			value = row[index]
+		}
+		i, ok := positions[value]
+		if !ok {
+			i = len(sheets)
+			positions[value] = i
+			sheets = append(sheets, Sheet{Name: value, Headers: headers})
+		}
+		sheets[i].Rows = append(sheets[i].Rows, row)
+	}
+	if len(sheets) == 0 {
+		sheets = append(sheets, Sheet{Name: column, Headers: headers})
+	}
+	return sheets, nil
+}
+
+// WriteXLSX writes worksheets as an Excel workbook.
+//
+// Headers are bold and frozen, and columns are sized to their content. Cells holding RFC3339
+// timestamps are written as dates in the timezone of the options, and cells holding numbers as
+// numbers, except in the columns whose header ends with "ID". Other cells are written as text.
+//
+// Parameters:
+//   - w: The destination of the workbook.
+//   - sheets: The worksheets of the workbook, at least one.
+//   - opts: The timezone of the dates.
+//
+// Returns:
+//   - error: An error if the workbook cannot be written.
+func WriteXLSX(w io.Writer, sheets []Sheet, opts XLSXOptions) error {
+	if len(sheets) == 0 {
+		return fmt.Errorf("a workbook needs at least one sheet")
+	}
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	names := sheetNames(sheets)
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypesXML(len(sheets))},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/workbook.xml", workbookXML(names)},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXML(len(sheets))},
+		{"xl/styles.xml", stylesXML},
+	}
+	for i, s := range sheets {
+		files = append(files, struct {
+			name    string
+			content string
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), sheetXML(s, loc)})
+	}
+
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return fmt.Errorf("error writing XLSX: %v", err)
+		}
+		if _, err := io.WriteString(fw, f.content); err != nil {
+			return fmt.Errorf("error writing XLSX: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("error writing XLSX: %v", err)
+	}
+	return nil
+}
+
+// sheetNames returns valid and unique names for worksheets.
+func sheetNames(sheets []Sheet) []string {
+	replacer := strings.NewReplacer("[", "(", "]", ")", ":", "-", "*", "-", "?", "", "/", "-", "\\", "-")
+	used := map[string]bool{}
+	names := make([]string, len(sheets))
+	for i, s := range sheets {
+		base := strings.TrimSpace(replacer.Replace(s.Name))
+		base = strings.Trim(base, "'")
+		if base == "" {
+			base = "Sheet"
+		}
+		name := truncate(base, maxSheetName)
+		for n := 2; used[strings.ToLower(name)]; n++ {
+			suffix := fmt.Sprintf(" (%d)", n)
+			name = truncate(base, maxSheetName-len(suffix)) + suffix
+		}
+		used[strings.ToLower(name)] = true
+		names[i] = name
+	}
+	return names
+}
+
+// truncate shortens a string to at most n characters.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// sheetXML renders a worksheet.
+func sheetXML(s Sheet, loc *time.Location) string {
+	widths := make([]int, len(s.Headers))
+	for i, h := range s.Headers {
+		widths[i] = utf8.RuneCountInString(h)
+	}
+
+	var rows strings.Builder
+	rows.WriteString(`<row r="1">`)
+	for i, h := range s.Headers {
+		fmt.Fprintf(&rows, `<c r="%s1" t="inlineStr" s="1"><is><t xml:space="preserve">%s</t></is></c>`, columnName(i), escapeXML(h))
+	}
+	rows.WriteString(`</row>`)
+
+	for r, row := range s.Rows {
+		n := r + 2
+		fmt.Fprintf(&rows, `<row r="%d">`, n)
+		for i, value := range row {
+			if value == "" {
+				continue
+			}
+			ref := columnName(i) + strconv.Itoa(n)
+			width := utf8.RuneCountInString(value)
+			isID := i < len(s.Headers) && strings.HasSuffix(strings.ToLower(s.Headers[i]), "id")
+			if t, err := time.Parse(time.RFC3339, value); err == nil {
+				fmt.Fprintf(&rows, `<c r="%s" s="2"><v>%s</v></c>`, ref, strconv.FormatFloat(serialDate(t, loc), 'f', -1, 64))
+				width = len(dateLayout)
+			} else if isNumber(value) && !isID {
+				fmt.Fprintf(&rows, `<c r="%s"><v>%s</v></c>`, ref, value)
+			} else {
+				fmt.Fprintf(&rows, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, escapeXML(value))
+			}
+			for len(widths) <= i {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], width)
+		}
+		rows.WriteString(`</row>`)
+	}
+
+	var cols strings.Builder
+	for i, width := range widths {
+		fmt.Fprintf(&cols, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, min(width+2, maxColumnWidth))
+	}
+
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	if cols.Len() > 0 {
+		b.WriteString(`<cols>` + cols.String() + `</cols>`)
+	}
+	b.WriteString(`<sheetData>` + rows.String() + `</sheetData></worksheet>`)
+	return b.String()
+}
+
+// isNumber reports whether a value is a decimal number.
+func isNumber(value string) bool {
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil && !strings.ContainsAny(value, "aAeEfFiInNpPxX_")
+}
+
+// serialDate converts a time to a serial date of Excel, as displayed in a timezone.
+func serialDate(t time.Time, loc *time.Location) float64 {
+	local := t.In(loc)
+	wall := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), time.UTC)
+	return wall.Sub(excelEpoch).Hours() / 24
+}
+
+// columnName returns the name of a column from its index, e.g. "A" for 0 and "AA" for 26.
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// escapeXML escapes text for XML, replacing the characters XML cannot hold.
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// contentTypesXML renders the content types of a workbook.
+func contentTypesXML(sheets int) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+	}
+	b.WriteString(`</Types>`)
+	return b.String()
+}
+
+// rootRelsXML is the package relationships of a workbook.
+const rootRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+// workbookXML renders the list of the worksheets of a workbook.
+func workbookXML(names []string) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, name := range names {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escapeXML(name), i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	return b.String()
+}
+
+// workbookRelsXML renders the relationships of a workbook to its worksheets and styles.
+func workbookRelsXML(sheets int) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := 1; i <= sheets; i++ {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, sheets+1)
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+// stylesXML holds the cell styles of a workbook: 0 for text and numbers, 1 for headers and 2
+// for dates.
+const stylesXML = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="1"><numFmt numFmtId="164" formatCode="` + dateLayout + `"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="3">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`</styleSheet>`
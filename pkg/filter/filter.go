@@ -0,0 +1,493 @@
+// Package filter selects events of a series with filter expressions.
+//
+// A filter expression is a list of terms separated by spaces, all of which must match:
+//
+//	type=player-killed-player,team-*   event type, with shell patterns, any of a comma separated list
+//	game=3                             game number, any of a comma separated list
+//	game>=2                            game number compared with !=, >, >=, < or <=
+//	actor=p1                           actor ID or name, any of a comma separated list
+//	target=dragon                      target ID or name, any of a comma separated list
+//	since=14:00 until=20m              time window, relative to the game start, or RFC3339 timestamps
+//	actor.stateDelta.kills>=2          predicate on any field of the event
+//
+// Field predicates use the JSON field names of events.Envelope, separated by dots, with
+// numeric segments indexing arrays. The operators are =, !=, >, >=, <, <= and ~ (contains).
+// Values that contain spaces can be enclosed in double quotes.
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+)
+
+// operators lists the predicate operators, longest first so that they are matched greedily.
+var operators = []string{">=", "<=", "!=", "=", ">", "<", "~"}
+
+// Predicate compares a field of an event with a value.
+type Predicate struct {
+	Field string // Field is the dot separated path of the field.
+	Op    string // Op is the comparison operator.
+	Value string // Value is the value compared with the field.
+}
+
+// GameCondition compares the game number of an event with a number.
+type GameCondition struct {
+	Op     string // Op is the comparison operator: !=, >, >=, < or <=.
+	Number int    // Number is the game number compared with.
+}
+
+// Bound is a limit of a time window.
+type Bound struct {
+	Relative bool          // Relative indicates whether the bound is relative to the game start.
+	Offset   time.Duration // Offset is the time since the game start of a relative bound.
+	Time     time.Time     // Time is the timestamp of an absolute bound.
+}
+
+// Filter is a parsed filter expression.
+type Filter struct {
+	Types      []string        // Types are the patterns of the accepted event types.
+	Games      []int           // Games are the accepted game numbers.
+	GameConds  []GameCondition // GameConds are the comparisons the game number must satisfy.
+	Actors     []string        // Actors are the accepted actor IDs or names.
+	Targets    []string        // Targets are the accepted target IDs or names.
+	Since      *Bound          // Since is the start of the time window, if any.
+	Until      *Bound          // Until is the end of the time window, if any.
+	Predicates []Predicate     // Predicates are the field predicates.
+}
+
+// Match is an event selected by a filter, with its game context.
+type Match struct {
+	events.Envelope
+	GameNumber int           `json:"gameNumber"` // GameNumber is the number of the game in progress, 0 between games.
+	GameTime   time.Duration `json:"-"`          // GameTime is the time elapsed since the start of the game in progress.
+}
+
+// Parse parses a filter expression. An empty expression matches every event.
+//
+// Parameters:
+//   - expr: The filter expression.
+//
+// Returns:
+//   - *Filter: The parsed filter.
+//   - error: An error if a term is invalid.
+func Parse(expr string) (*Filter, error) {
+	terms, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Filter{}
+	for _, term := range terms {
+		p, err := parsePredicate(term)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case p.Op == "=":
+		case p.Field == "since" || p.Field == "until":
+			return nil, fmt.Errorf("%s only supports =", p.Field)
+		case p.Field == "game":
+			c, err := parseGameCondition(p)
+			if err != nil {
+				return nil, err
+			}
+			f.GameConds = append(f.GameConds, c...)
+			continue
+		default:
+			f.Predicates = append(f.Predicates, p)
+			continue
+		}
+		switch p.Field {
+		case "type":
+			f.Types = append(f.Types, splitValues(p.Value)...)
+		case "game":
+			for _, v := range splitValues(p.Value) {
+				n, err := strconv.Atoi(v)
+				if err != nil {
+					return nil, fmt.Errorf("invalid game number %q", v)
+				}
+				f.Games = append(f.Games, n)
+			}
+		case "actor":
+			f.Actors = append(f.Actors, splitValues(p.Value)...)
+		case "target":
+			f.Targets = append(f.Targets, splitValues(p.Value)...)
+		case "since", "until":
+			b, err := ParseBound(p.Value)
+			if err != nil {
+				return nil, err
+			}
+			if p.Field == "since" {
+				f.Since = &b
+			} else {
+				f.Until = &b
+			}
+		default:
+			f.Predicates = append(f.Predicates, p)
+		}
+	}
+	return f, nil
+}
+
+// parseGameCondition parses a comparison of the game number. A comma separated list is only
+// accepted with !=, excluding each of its games.
+func parseGameCondition(p Predicate) ([]GameCondition, error) {
+	if p.Op == "~" {
+		return nil, fmt.Errorf("game does not support ~; use =, !=, >, >=, < or <=")
+	}
+	values := splitValues(p.Value)
+	if len(values) == 0 || (len(values) > 1 && p.Op != "!=") {
+		return nil, fmt.Errorf("invalid game condition %q: expected a single game number", "game"+p.Op+p.Value)
+	}
+	var conds []GameCondition
+	for _, v := range values {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid game number %q", v)
+		}
+		conds = append(conds, GameCondition{Op: p.Op, Number: n})
+	}
+	return conds, nil
+}
+
+// tokenize splits an expression into terms, keeping double quoted values together.
+func tokenize(expr string) ([]string, error) {
+	var terms []string
+	var current strings.Builder
+	inQuotes := false
+	for _, r := range expr {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case (r == ' ' || r == '\t' || r == '\n') && !inQuotes:
+			if current.Len() > 0 {
+				terms = append(terms, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in filter expression")
+	}
+	if current.Len() > 0 {
+		terms = append(terms, current.String())
+	}
+	return terms, nil
+}
+
+// parsePredicate splits a term into a field, an operator and a value.
+func parsePredicate(term string) (Predicate, error) {
+	best := -1
+	bestOp := ""
+	for _, op := range operators {
+		if i := strings.Index(term, op); i > 0 && (best == -1 || i < best || (i == best && len(op) > len(bestOp))) {
+			best, bestOp = i, op
+		}
+	}
+	if best == -1 {
+		return Predicate{}, fmt.Errorf("invalid filter term %q: expected field, operator and value", term)
+	}
+	return Predicate{Field: term[:best], Op: bestOp, Value: term[best+len(bestOp):]}, nil
+}
+
+// splitValues splits a comma separated list of values.
+func splitValues(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// ParseBound parses a time window bound.
+//
+// Relative bounds are written as minutes and seconds ("14:05") or as a Go duration ("14m5s"),
+// absolute bounds as RFC3339 timestamps.
+func ParseBound(value string) (Bound, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return Bound{Time: t}, nil
+	}
+	if d, err := ParseGameTime(value); err == nil {
+		return Bound{Relative: true, Offset: d}, nil
+	}
+	return Bound{}, fmt.Errorf("invalid time %q: expected mm:ss, a duration or an RFC3339 timestamp", value)
+}
+
+// ParseGameTime parses a time since the game start written as "mm:ss", "hh:mm:ss" or a Go duration.
+func ParseGameTime(value string) (time.Duration, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return d, nil
+	}
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid game time %q", value)
+	}
+	var total time.Duration
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid game time %q", value)
+		}
+		total = total*60 + time.Duration(n)
+	}
+	return total * time.Second, nil
+}
+
+// Apply returns an iterator over the events matching the filter.
+//
+// Parameters:
+//   - seq: An iterator over the events of a series, in order.
+//
+// Returns:
+//   - iter.Seq2[Match, error]: An iterator over the matching events with their game context.
+//     The iteration stops after the first error.
+func (f *Filter) Apply(seq iter.Seq2[events.Envelope, error]) iter.Seq2[Match, error] {
+	return func(yield func(Match, error) bool) {
+		gameNumber := 0
+		inGame := false
+		var gameStart time.Time
+		for env, err := range seq {
+			if err != nil {
+				yield(Match{}, err)
+				return
+			}
+			if env.Type == "series-started-game" {
+				gameNumber++
+				inGame = true
+				gameStart = env.OccurredAt
+			}
+
+			m := Match{Envelope: env}
+			if inGame {
+				m.GameNumber = gameNumber
+				m.GameTime = env.OccurredAt.Sub(gameStart)
+			}
+			if env.Type == "series-ended-game" {
+				inGame = false
+			}
+
+			ok, err := f.Matches(m)
+			if err != nil {
+				yield(Match{}, err)
+				return
+			}
+			if ok && !yield(m, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Matches reports whether an event with its game context matches the filter.
+func (f *Filter) Matches(m Match) (bool, error) {
+	if len(f.Types) > 0 && !matchesPattern(f.Types, m.Type) {
+		return false, nil
+	}
+	if len(f.Games) > 0 && !containsInt(f.Games, m.GameNumber) {
+		return false, nil
+	}
+	for _, c := range f.GameConds {
+		if !c.matches(m.GameNumber) {
+			return false, nil
+		}
+	}
+	if len(f.Actors) > 0 && !matchesEntity(f.Actors, m.Actor) {
+		return false, nil
+	}
+	if len(f.Targets) > 0 && !matchesEntity(f.Targets, m.Target) {
+		return false, nil
+	}
+	if f.Since != nil && !f.Since.before(m, true) {
+		return false, nil
+	}
+	if f.Until != nil && !f.Until.before(m, false) {
+		return false, nil
+	}
+	if len(f.Predicates) == 0 {
+		return true, nil
+	}
+
+	doc, err := document(m.Envelope)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range f.Predicates {
+		if !p.matches(doc) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// before checks an event against a bound: with since set, the event must not be before the
+// bound, otherwise it must not be after it. Relative bounds never match events between games.
+func (b Bound) before(m Match, since bool) bool {
+	if b.Relative {
+		if m.GameNumber == 0 {
+			return false
+		}
+		if since {
+			return m.GameTime >= b.Offset
+		}
+		return m.GameTime <= b.Offset
+	}
+	if since {
+		return !m.OccurredAt.Before(b.Time)
+	}
+	return !m.OccurredAt.After(b.Time)
+}
+
+// matches reports whether a game number satisfies the condition. Events between games,
+// with no game number, never match.
+func (c GameCondition) matches(game int) bool {
+	if game == 0 {
+		return false
+	}
+	switch c.Op {
+	case "!=":
+		return game != c.Number
+	case ">":
+		return game > c.Number
+	case ">=":
+		return game >= c.Number
+	case "<":
+		return game < c.Number
+	case "<=":
+		return game <= c.Number
+	}
+	return false
+}
+
+// matchesPattern reports whether a value matches any of the shell patterns.
+func matchesPattern(patterns []string, value string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// containsInt reports whether a slice contains a value.
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesEntity reports whether an entity has one of the IDs or names.
+func matchesEntity(values []string, e events.Entity) bool {
+	name := e.Name()
+	for _, v := range values {
+		if v == e.ID || (name != "" && strings.EqualFold(v, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// document converts an event to generic JSON values for field predicates.
+func document(env events.Envelope) (any, error) {
+	data, err := json.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding event %s: %v", env.ID, err)
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error decoding event %s: %v", env.ID, err)
+	}
+	return doc, nil
+}
+
+// Lookup resolves a dot separated field path in a generic JSON document.
+func Lookup(doc any, field string) (any, bool) {
+	current := doc
+	for _, segment := range strings.Split(field, ".") {
+		switch v := current.(type) {
+		case map[string]any:
+			next, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []any:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			current = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// matches evaluates the predicate on a generic JSON document. Missing fields never match.
+func (p Predicate) matches(doc any) bool {
+	value, ok := Lookup(doc, p.Field)
+	if !ok || value == nil {
+		return false
+	}
+
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		text = strconv.FormatBool(v)
+	default:
+		data, _ := json.Marshal(v)
+		text = string(data)
+	}
+
+	if p.Op == "~" {
+		return strings.Contains(strings.ToLower(text), strings.ToLower(p.Value))
+	}
+
+	cmp := strings.Compare(text, p.Value)
+	if a, err := strconv.ParseFloat(text, 64); err == nil {
+		if b, err := strconv.ParseFloat(p.Value, 64); err == nil {
+			switch {
+			case a < b:
+				cmp = -1
+			case a > b:
+				cmp = 1
+			default:
+				cmp = 0
+			}
+		}
+	}
+
+	switch p.Op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+)
+
+const testEvents = `{"id":"tx-1","sequenceNumber":1,"occurredAt":"2024-05-10T12:00:00Z","seriesId":"1","events":[{"id":"ev-1","type":"series-started-game","actor":{"type":"series","id":"1"},"target":{"type":"game","id":"g1"}}]}
+{"id":"tx-2","sequenceNumber":2,"occurredAt":"2024-05-10T12:05:00Z","seriesId":"1","events":[{"id":"ev-2","type":"player-killed-player","actor":{"type":"player","id":"p1","state":{"name":"Faker"},"stateDelta":{"kills":1}},"target":{"type":"player","id":"p2"}}]}
+{"id":"tx-3","sequenceNumber":3,"occurredAt":"2024-05-10T12:20:00Z","seriesId":"1","events":[{"id":"ev-3","type":"team-killed-dragon","actor":{"type":"team","id":"t1"},"target":{"type":"dragon","id":"infernal"}}]}
+{"id":"tx-4","sequenceNumber":4,"occurredAt":"2024-05-10T12:30:00Z","seriesId":"1","events":[{"id":"ev-4","type":"series-ended-game","actor":{"type":"series","id":"1"},"target":{"type":"game","id":"g1"}}]}
+{"id":"tx-5","sequenceNumber":5,"occurredAt":"2024-05-10T12:40:00Z","seriesId":"1","events":[{"id":"ev-5","type":"series-started-game","actor":{"type":"series","id":"1"},"target":{"type":"game","id":"g2"}}]}
+{"id":"tx-6","sequenceNumber":6,"occurredAt":"2024-05-10T12:50:00Z","seriesId":"1","events":[{"id":"ev-6","type":"team-killed-dragon","actor":{"type":"team","id":"t2"},"target":{"type":"dragon","id":"ocean"}}]}
+`
+
+// matchIDs returns the IDs of the test events matching an expression.
+func matchIDs(t *testing.T, expr string) string {
+	t.Helper()
+	f, err := Parse(expr)
+	if err != nil {
+		t.Fatalf("Failed to parse %q: %v", expr, err)
+	}
+	var ids []string
+	for m, err := range f.Apply(events.Flatten(events.ReadTransactions(strings.NewReader(testEvents)))) {
+		if err != nil {
+			t.Fatalf("Failed to apply %q: %v", expr, err)
+		}
+		ids = append(ids, m.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestFilter(t *testing.T) {
+	cases := map[string]string{
+		"":                                  "ev-1,ev-2,ev-3,ev-4,ev-5,ev-6",
+		"type=*-killed-dragon":              "ev-3,ev-6",
+		"type=*-killed-dragon game=2":       "ev-6",
+		"actor=faker":                       "ev-2",
+		"since=10:00 until=25m":             "ev-3,ev-6",
+		"since=2024-05-10T12:45:00Z":        "ev-6",
+		"actor.stateDelta.kills>=1":         "ev-2",
+		"target.id~OCE":                     "ev-6",
+		`target.type!=dragon game=1`:        "ev-1,ev-2,ev-4",
+		`type="series-started-game" game=2`: "ev-5",
+		"game!=2":                           "ev-1,ev-2,ev-3,ev-4",
+		"game!=1,2":                         "",
+		"game>=2":                           "ev-5,ev-6",
+		"game>1":                            "ev-5,ev-6",
+		"game<2":                            "ev-1,ev-2,ev-3,ev-4",
+		"game<=1 type=*-killed-dragon":      "ev-3",
+	}
+	for expr, expected := range cases {
+		if got := matchIDs(t, expr); got != expected {
+			t.Errorf("Expected %q to match %s, but got %s", expr, expected, got)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{"type", "game=x", "since=later", `type="open`,
+		"game~2", "game>x", "game>=1,2", "since>10:00", "since!=10:00", "until<20:00", "until~20:00"} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Expected %q to be rejected", expr)
+		}
+	}
+}
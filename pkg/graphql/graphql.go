@@ -17,6 +17,10 @@ import (
 	"github.com/simplesmentemat/stealth-grid-cli/pkg/config"
 )
 
+// EventsFileID is the identifier used for the compressed series events file
+// in download options and in the local download library.
+const EventsFileID = "events-grid-compressed"
+
 // QueryVariables represents the variables for the GraphQL query.
 type QueryVariables struct {
 	StartTime   string `json:"startTime"`
@@ -56,10 +60,29 @@ type GraphQLRequest struct {
 //     marshalling of the request, creation of the HTTP request, sending the HTTP
 //     request, or decoding the JSON response.
 func FetchData(titleID string, startTime, endTime time.Time) (map[string]interface{}, error) {
+	return FetchPage(titleID, startTime, endTime, "")
+}
+
+// FetchPage fetches a page of the series of a title within a time range.
+//
+// It sends the query of FetchData, starting after the given cursor. Use ParseSeriesPage to
+// read the series of the page and the cursor of the next page.
+//
+// Parameters:
+//   - titleID: The ID of the title to query for.
+//   - startTime: The start of the time range.
+//   - endTime: The end of the time range.
+//   - afterCursor: The end cursor of the previous page, or an empty string for the first page.
+//
+// Returns:
+//   - map[string]interface{}: The decoded response.
+//   - error: An error if the request fails, the server answers with an error status or the
+//     response holds GraphQL errors.
+func FetchPage(titleID string, startTime, endTime time.Time, afterCursor string) (map[string]interface{}, error) {
 	variables := QueryVariables{
 		StartTime:   startTime.Format(time.RFC3339),
 		EndTime:     endTime.Format(time.RFC3339),
-		AfterCursor: "",
+		AfterCursor: afterCursor,
 		TitleIDs:    titleID,
 	}
 
@@ -82,6 +105,11 @@ func FetchData(titleID string, startTime, endTime time.Time) (map[string]interfa
 						id
 					}
 					startTimeScheduled
+					title {
+						id
+						name
+						nameShortened
+					}
 					format {
 						nameShortened
 					}
@@ -96,12 +124,80 @@ func FetchData(titleID string, startTime, endTime time.Time) (map[string]interfa
 		}
 	}`
 
-	graphQLReq := GraphQLRequest{
-		Query:     query,
-		Variables: variables,
+	return postQuery(GraphQLRequest{Query: query, Variables: variables})
+}
+
+// FetchSeries fetches the metadata of a single series by its ID.
+//
+// Parameters:
+//   - seriesID: The ID of the series.
+//
+// Returns:
+//   - Series: The metadata of the series, with the same fields as the series of FetchPage.
+//   - error: An error if the request fails, the response holds GraphQL errors or the series does not exist.
+func FetchSeries(seriesID string) (Series, error) {
+	query := `query GetSeries($id: ID!) {
+		series(id: $id) {
+			id
+			tournament {
+				nameShortened
+				name
+				id
+			}
+			startTimeScheduled
+			title {
+				id
+				name
+				nameShortened
+			}
+			format {
+				nameShortened
+			}
+			teams {
+				baseInfo {
+					name
+					id
+				}
+			}
+		}
+	}`
+
+	result, err := postQuery(struct {
+		Query     string            `json:"query"`
+		Variables map[string]string `json:"variables"`
+	}{Query: query, Variables: map[string]string{"id": seriesID}})
+	if err != nil {
+		return Series{}, err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return Series{}, fmt.Errorf("error encoding response: %v", err)
+	}
+	var response struct {
+		Data *struct {
+			Series *Series `json:"series"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return Series{}, fmt.Errorf("error decoding series: %v", err)
 	}
+	if response.Data == nil || response.Data.Series == nil {
+		return Series{}, fmt.Errorf("series %s not found", seriesID)
+	}
+	return *response.Data.Series, nil
+}
 
-	reqBody, err := json.Marshal(graphQLReq)
+// postQuery sends a GraphQL request to the Central Data API.
+//
+// Parameters:
+//   - request: The request, encoded to JSON as the body of the HTTP request.
+//
+// Returns:
+//   - map[string]interface{}: The decoded response.
+//   - error: An error if the request fails, the status code is not OK or the response
+//     holds GraphQL errors.
+func postQuery(request any) (map[string]interface{}, error) {
+	reqBody, err := json.Marshal(request)
 	if err != nil {
 		return nil, fmt.Errorf("error marshalling GraphQL request: %v", err)
 	}
@@ -122,10 +218,20 @@ func FetchData(titleID string, startTime, endTime time.Time) (map[string]interfa
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching series: status code %d", resp.StatusCode)
+	}
+
 	var result map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, fmt.Errorf("error decoding JSON response: %v", err)
 	}
+	if errs, ok := result["errors"].([]interface{}); ok && len(errs) > 0 {
+		if e, ok := errs[0].(map[string]interface{}); ok {
+			return nil, fmt.Errorf("error fetching series: %v", e["message"])
+		}
+		return nil, fmt.Errorf("error fetching series: %v", errs[0])
+	}
 
 	return result, nil
 }
@@ -150,12 +256,12 @@ func FetchData(titleID string, startTime, endTime time.Time) (map[string]interfa
 //  5. Checks if the response status code is OK (200). If not, logs an error and terminates.
 //  6. Creates a file to save the downloaded ZIP content.
 //  7. Copies the content from the response body to the created file.
-//  8. Logs a success message if the file is saved successfully, or an error message if any step fails.
-func DownloadJSON(serieID string, directory string) error {
+//  8. Returns the path of the saved file, or an error if any step fails.
+func DownloadJSON(serieID string, directory string) (string, error) {
 	url := fmt.Sprintf("%s/file-download/events/grid/series/%s", config.APIURL, serieID)
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return fmt.Errorf("erro ao criar solicitação: %v", err)
+		return "", fmt.Errorf("erro ao criar solicitação: %v", err)
 	}
 
 	apiKey := config.GetAPIKey()
@@ -164,32 +270,32 @@ func DownloadJSON(serieID string, directory string) error {
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("erro ao baixar o ZIP: %v", err)
+		return "", fmt.Errorf("erro ao baixar o ZIP: %v", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("erro: código de status %d", resp.StatusCode)
+		return "", fmt.Errorf("erro: código de status %d", resp.StatusCode)
 	}
 
 	// Verificar se o diretório existe e é acessível
 	if _, err := os.Stat(directory); os.IsNotExist(err) {
-		return fmt.Errorf("o diretório não existe: %s", directory)
+		return "", fmt.Errorf("o diretório não existe: %s", directory)
 	}
 
 	filePath := filepath.Join(directory, fmt.Sprintf("%s.zip", serieID))
 	file, err := os.Create(filePath)
 	if err != nil {
-		return fmt.Errorf("erro ao criar o arquivo: %v", err)
+		return "", fmt.Errorf("erro ao criar o arquivo: %v", err)
 	}
 	defer file.Close()
 
 	_, err = io.Copy(file, resp.Body)
 	if err != nil {
-		return fmt.Errorf("erro ao salvar o ZIP no arquivo: %v", err)
+		return "", fmt.Errorf("erro ao salvar o ZIP no arquivo: %v", err)
 	}
 
-	return nil
+	return filePath, nil
 }
 
 // DownloadGame downloads a replay file for a given series ID and game ID from the specified API.
@@ -214,13 +320,13 @@ func DownloadJSON(serieID string, directory string) error {
 //  5. Checks if the response status code is OK (200). If not, logs an error and terminates.
 //  6. Creates a file to save the downloaded replay content.
 //  7. Copies the content from the response body to the created file.
-//  8. Logs a success message if the file is saved successfully, or an error message if any step fails.
-func DownloadGame(seriesID string, gameID string, directory string) error {
+//  8. Returns the path of the saved file, or an error if any step fails.
+func DownloadGame(seriesID string, gameID string, directory string) (string, error) {
 	url := fmt.Sprintf("%s/file-download/replay/riot/series/%s/games/%s", config.APIURL, seriesID, gameID)
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return fmt.Errorf("erro ao criar solicitação: %v", err)
+		return "", fmt.Errorf("erro ao criar solicitação: %v", err)
 	}
 
 	apiKey := config.GetAPIKey()
@@ -229,31 +335,48 @@ func DownloadGame(seriesID string, gameID string, directory string) error {
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("erro ao baixar o ZIP: %v", err)
+		return "", fmt.Errorf("erro ao baixar o ZIP: %v", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("erro: código de status %d", resp.StatusCode)
+		return "", fmt.Errorf("erro: código de status %d", resp.StatusCode)
 	}
 
 	if _, err := os.Stat(directory); os.IsNotExist(err) {
-		return fmt.Errorf("o diretório não existe: %s", directory)
+		return "", fmt.Errorf("o diretório não existe: %s", directory)
 	}
 
 	filePath := filepath.Join(directory, fmt.Sprintf("%s-%s.rofl", seriesID, gameID))
 	file, err := os.Create(filePath)
 	if err != nil {
-		return fmt.Errorf("erro ao criar o arquivo: %v", err)
+		return "", fmt.Errorf("erro ao criar o arquivo: %v", err)
 	}
 	defer file.Close()
 
 	_, err = io.Copy(file, resp.Body)
 	if err != nil {
-		return fmt.Errorf("erro ao salvar o ROFL no arquivo: %v", err)
+		return "", fmt.Errorf("erro ao salvar o ROFL no arquivo: %v", err)
 	}
 
-	return nil
+	return filePath, nil
+}
+
+// DownloadFile downloads a file of a series identified by its download option.
+//
+// Parameters:
+//   - seriesID: A string representing the ID of the series to download the file for.
+//   - fileID: EventsFileID to download the events ZIP file, or a game number to download its replay.
+//   - directory: A string representing the directory where the file will be saved.
+//
+// Returns:
+//   - The path of the saved file.
+//   - An error if the download fails at any point.
+func DownloadFile(seriesID string, fileID string, directory string) (string, error) {
+	if fileID == EventsFileID {
+		return DownloadJSON(seriesID, directory)
+	}
+	return DownloadGame(seriesID, fileID, directory)
 }
 
 // FetchGameList fetches the list of game files for a given series ID.
@@ -1,10 +1,13 @@
 package graphql
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -48,6 +51,10 @@ func TestDownloadJSON(t *testing.T) {
 		t.Fatalf("Failed to create temp config file: %v", err)
 	}
 	defer os.Remove(configPath.Name())
+	if _, err := configPath.WriteString("api_key: test-key\n"); err != nil {
+		t.Fatalf("Failed to write temp config file: %v", err)
+	}
+	configPath.Close()
 
 	// Check and print the config type and content for debugging
 	fmt.Printf("Config type: %s\n", viper.GetViper().ConfigFileUsed())
@@ -71,3 +78,109 @@ func TestDownloadJSON(t *testing.T) {
 		t.Fatalf("Expected file 2620066.zip to be created, but it does not exist")
 	}
 }
+
+func TestWaitForFiles(t *testing.T) {
+	checks := 0
+	handler := http.NewServeMux()
+	handler.HandleFunc("/file-download/list/2620066", func(w http.ResponseWriter, r *http.Request) {
+		checks++
+		w.Header().Set("Content-Type", "application/json")
+		if checks < 3 {
+			w.Write([]byte(`{"files": []}`))
+			return
+		}
+		w.Write([]byte(`{"files": [{"id": "events-grid", "fileName": "events.zip"}, {"id": "replay-1", "fileName": "game-1.rofl"}]}`))
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	config.APIURL = server.URL
+
+	backoff := Backoff{Initial: time.Millisecond, Max: 5 * time.Millisecond, Factor: 2}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var attempts int
+	err := WaitForFiles(ctx, "2620066", []string{EventsFileID, "1"}, backoff, func(p WaitProgress) {
+		attempts = p.Attempt
+	})
+	if err != nil {
+		t.Fatalf("Failed to wait for files: %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("Expected 2 reported attempts before the files were ready, but got %d", attempts)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := WaitForFiles(ctx, "2620066", []string{"2"}, backoff, nil); err == nil {
+		t.Fatalf("Expected waiting for a missing game to time out")
+	}
+}
+
+func TestSeriesPages(t *testing.T) {
+	handler := http.NewServeMux()
+	handler.HandleFunc("/central-data/graphql", func(w http.ResponseWriter, r *http.Request) {
+		var req GraphQLRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		switch req.Variables.AfterCursor {
+		case "":
+			w.Write([]byte(`{"data": {"allSeries": {"totalCount": 3, "pageInfo": {"hasNextPage": true, "endCursor": "c2"},
+				"edges": [{"node": {"id": "1"}}, {"node": {"id": "2"}}]}}}`))
+		case "c2":
+			w.Write([]byte(`{"data": {"allSeries": {"totalCount": 3, "pageInfo": {"hasNextPage": false, "endCursor": "c3"},
+				"edges": [{"node": {"id": "3", "title": {"id": "3", "nameShortened": "LoL"}}}]}}}`))
+		default:
+			w.Write([]byte(`{"errors": [{"message": "invalid cursor"}]}`))
+		}
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	config.APIURL = server.URL
+
+	var pages [][]Series
+	for page, err := range SeriesPages("3", time.Now(), time.Now()) {
+		if err != nil {
+			t.Fatalf("Failed to fetch series: %v", err)
+		}
+		pages = append(pages, page)
+	}
+	if len(pages) != 2 || len(pages[0]) != 2 || pages[1][0].ID != "3" || pages[1][0].Title.NameShortened != "LoL" {
+		t.Fatalf("Unexpected pages %+v", pages)
+	}
+
+	if _, err := FetchPage("3", time.Now(), time.Now(), "bad"); err == nil || !strings.Contains(err.Error(), "invalid cursor") {
+		t.Fatalf("Expected the GraphQL error to be returned, but got %v", err)
+	}
+}
+
+func TestFetchSeries(t *testing.T) {
+	handler := http.NewServeMux()
+	handler.HandleFunc("/central-data/graphql", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables map[string]string `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		if req.Variables["id"] != "2620066" {
+			w.Write([]byte(`{"data": {"series": null}}`))
+			return
+		}
+		w.Write([]byte(`{"data": {"series": {"id": "2620066", "title": {"id": "3"}, "tournament": {"name": "LEC"},
+			"teams": [{"baseInfo": {"name": "Team 1"}}, {"baseInfo": {"name": "Team 2"}}]}}}`))
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	config.APIURL = server.URL
+
+	series, err := FetchSeries("2620066")
+	if err != nil {
+		t.Fatalf("Failed to fetch series: %v", err)
+	}
+	if series.Title.ID != "3" || series.Tournament.Name != "LEC" || len(series.TeamNames()) != 2 {
+		t.Fatalf("Unexpected series %+v", series)
+	}
+	if _, err := FetchSeries("1"); err == nil {
+		t.Fatalf("Expected an error for an unknown series")
+	}
+}
@@ -0,0 +1,209 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Series represents a series returned by the allSeries query of FetchData.
+type Series struct {
+	ID                 string       `json:"id"`                 // ID is the GRID series ID.
+	StartTimeScheduled string       `json:"startTimeScheduled"` // StartTimeScheduled is the scheduled start time in RFC3339 format.
+	Title              Title        `json:"title"`              // Title is the game title of the series.
+	Tournament         Tournament   `json:"tournament"`         // Tournament is the tournament of the series.
+	Format             Format       `json:"format"`             // Format is the format of the series, e.g. Bo3.
+	Teams              []SeriesTeam `json:"teams"`              // Teams are the teams playing the series.
+	Result             *Result      `json:"-"`                  // Result is the outcome of the series read from its downloaded events, nil if unknown.
+}
+
+// Result is the outcome of a series, which the API does not return with the series. It is
+// read from the events archives ingested into the warehouse.
+type Result struct {
+	Scores map[string]int // Scores are the numbers of games won, by team ID.
+	Winner string         // Winner is the name of the team that won the series, empty if it is not over.
+}
+
+// Title represents the game title of a series.
+type Title struct {
+	ID            string `json:"id"`            // ID is the GRID title ID.
+	Name          string `json:"name"`          // Name is the name of the title.
+	NameShortened string `json:"nameShortened"` // NameShortened is the short name of the title, e.g. "LoL".
+}
+
+// Tournament represents the tournament of a series.
+type Tournament struct {
+	ID            string `json:"id"`            // ID is the GRID tournament ID.
+	Name          string `json:"name"`          // Name is the name of the tournament.
+	NameShortened string `json:"nameShortened"` // NameShortened is the short name of the tournament.
+}
+
+// Format represents the format of a series.
+type Format struct {
+	NameShortened string `json:"nameShortened"` // NameShortened is the short name of the format, e.g. "Bo3".
+}
+
+// BestOf returns the maximum number of games of the format, e.g. three for "Bo3", or 0 if the
+// format is unknown.
+func (f Format) BestOf() int {
+	n, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(f.NameShortened), "bo"))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// SeriesTeam represents a team playing a series.
+type SeriesTeam struct {
+	BaseInfo TeamInfo `json:"baseInfo"` // BaseInfo holds the identity of the team.
+}
+
+// TeamInfo holds the identity of a team.
+type TeamInfo struct {
+	ID   string `json:"id"`   // ID is the GRID team ID.
+	Name string `json:"name"` // Name is the name of the team.
+}
+
+// TeamNames returns the names of the teams of the series.
+func (s Series) TeamNames() []string {
+	names := make([]string, len(s.Teams))
+	for i, t := range s.Teams {
+		names[i] = t.BaseInfo.Name
+	}
+	return names
+}
+
+// SeriesPage is a page of the series returned by FetchPage.
+type SeriesPage struct {
+	Series      []Series // Series are the series of the page, in the order of the response.
+	TotalCount  int      // TotalCount is the number of series over all pages.
+	HasNextPage bool     // HasNextPage reports whether more series follow the page.
+	EndCursor   string   // EndCursor is the cursor to fetch the next page with.
+}
+
+// ParseSeries extracts the series from a result returned by FetchData.
+//
+// Parameters:
+//   - result: The decoded response of FetchData.
+//
+// Returns:
+//   - A slice of Series in the order of the response.
+//   - An error if the response does not hold a list of series.
+func ParseSeries(result map[string]interface{}) ([]Series, error) {
+	page, err := ParseSeriesPage(result)
+	if err != nil {
+		return nil, err
+	}
+	return page.Series, nil
+}
+
+// ParseSeriesPage extracts the series and the pagination of a result returned by FetchPage.
+//
+// Parameters:
+//   - result: The decoded response of FetchPage.
+//
+// Returns:
+//   - SeriesPage: The series of the page and the cursor of the next page.
+//   - error: An error if the response does not hold a list of series.
+func ParseSeriesPage(result map[string]interface{}) (SeriesPage, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return SeriesPage{}, fmt.Errorf("error encoding response: %v", err)
+	}
+
+	var response struct {
+		Data *struct {
+			AllSeries *struct {
+				TotalCount int `json:"totalCount"`
+				PageInfo   struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+				Edges []struct {
+					Node Series `json:"node"`
+				} `json:"edges"`
+			} `json:"allSeries"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return SeriesPage{}, fmt.Errorf("error decoding series: %v", err)
+	}
+	if response.Data == nil || response.Data.AllSeries == nil {
+		return SeriesPage{}, fmt.Errorf("no series found in response")
+	}
+
+	all := response.Data.AllSeries
+	page := SeriesPage{
+		Series:      make([]Series, len(all.Edges)),
+		TotalCount:  all.TotalCount,
+		HasNextPage: all.PageInfo.HasNextPage,
+		EndCursor:   all.PageInfo.EndCursor,
+	}
+	for i, edge := range all.Edges {
+		page.Series[i] = edge.Node
+	}
+	return page, nil
+}
+
+// SeriesPages fetches the series of a title within a time range, page after page.
+//
+// A page is only fetched when the previous one has been consumed, so that callers can write
+// the series as they arrive instead of waiting for all of them. Iteration stops after the
+// first error.
+//
+// Parameters:
+//   - titleID: The ID of the title to query for.
+//   - startTime: The start of the time range.
+//   - endTime: The end of the time range.
+//
+// Returns:
+//   - iter.Seq2[[]Series, error]: The series of each page, or the error that stopped the fetch.
+func SeriesPages(titleID string, startTime, endTime time.Time) iter.Seq2[[]Series, error] {
+	return func(yield func([]Series, error) bool) {
+		cursor := ""
+		for {
+			result, err := FetchPage(titleID, startTime, endTime, cursor)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			page, err := ParseSeriesPage(result)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(page.Series, nil) {
+				return
+			}
+			// A cursor that does not move would fetch the same page forever.
+			if !page.HasNextPage || page.EndCursor == "" || page.EndCursor == cursor {
+				return
+			}
+			cursor = page.EndCursor
+		}
+	}
+}
+
+// FetchAllSeries fetches every page of the series of a title within a time range.
+//
+// Parameters:
+//   - titleID: The ID of the title to query for.
+//   - startTime: The start of the time range.
+//   - endTime: The end of the time range.
+//
+// Returns:
+//   - []Series: The series of all pages, in order.
+//   - error: An error if a page cannot be fetched.
+func FetchAllSeries(titleID string, startTime, endTime time.Time) ([]Series, error) {
+	var series []Series
+	for page, err := range SeriesPages(titleID, startTime, endTime) {
+		if err != nil {
+			return nil, err
+		}
+		series = append(series, page...)
+	}
+	return series, nil
+}
@@ -0,0 +1,106 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Backoff configures the polling intervals used while waiting for files to become available.
+type Backoff struct {
+	Initial time.Duration // Initial is the delay before the second check.
+	Max     time.Duration // Max is the upper bound of the delay between two checks.
+	Factor  float64       // Factor is the multiplier applied to the delay after each check.
+}
+
+// DefaultBackoff is the backoff used when none is configured.
+var DefaultBackoff = Backoff{
+	Initial: 30 * time.Second,
+	Max:     10 * time.Minute,
+	Factor:  2,
+}
+
+// next returns the delay following the given one.
+func (b Backoff) next(delay time.Duration) time.Duration {
+	if delay <= 0 {
+		return b.Initial
+	}
+	delay = time.Duration(float64(delay) * b.Factor)
+	if b.Max > 0 && delay > b.Max {
+		delay = b.Max
+	}
+	return delay
+}
+
+// WaitProgress describes a check made while waiting for files to become available.
+type WaitProgress struct {
+	Attempt   int           // Attempt is the number of checks made so far.
+	RoflCount int           // RoflCount is the number of replay files available at this check.
+	HasJSON   bool          // HasJSON indicates whether the events file is available at this check.
+	Next      time.Duration // Next is the delay before the following check.
+	Err       error         // Err is the error returned by the check, if any.
+}
+
+// FilesReady reports whether the requested files are part of a game list.
+//
+// Parameters:
+//   - fileIDs: The requested files, either EventsFileID or game numbers.
+//   - roflCount: The number of replay files available for the series.
+//   - hasJSON: Whether the events file is available for the series.
+//
+// Returns:
+//   - A boolean indicating whether all the requested files are available.
+func FilesReady(fileIDs []string, roflCount int, hasJSON bool) bool {
+	for _, id := range fileIDs {
+		if id == EventsFileID {
+			if !hasJSON {
+				return false
+			}
+			continue
+		}
+		game, err := strconv.Atoi(id)
+		if err != nil || game > roflCount {
+			return false
+		}
+	}
+	return true
+}
+
+// WaitForFiles polls the game list of a series until the requested files are available.
+//
+// The game list is fetched with FetchGameList, first immediately and then after delays
+// growing according to the backoff. Errors returned by FetchGameList are reported and
+// retried, as the file-download API may not know about a series until it has ended.
+//
+// Parameters:
+//   - ctx: The context bounding the wait. Use context.WithTimeout to set a timeout.
+//   - seriesID: A string representing the ID of the series to wait for.
+//   - fileIDs: The requested files, either EventsFileID or game numbers.
+//   - backoff: The polling intervals to use.
+//   - progress: A function called after each check, or nil.
+//
+// Returns:
+//   - An error if the context is done before the files are available.
+func WaitForFiles(ctx context.Context, seriesID string, fileIDs []string, backoff Backoff, progress func(WaitProgress)) error {
+	var delay time.Duration
+	for attempt := 1; ; attempt++ {
+		roflCount, hasJSON, err := FetchGameList(seriesID)
+		if err == nil && FilesReady(fileIDs, roflCount, hasJSON) {
+			return nil
+		}
+
+		delay = backoff.next(delay)
+		if progress != nil {
+			progress(WaitProgress{Attempt: attempt, RoflCount: roflCount, HasJSON: hasJSON, Next: delay, Err: err})
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("files of series %s not available after %d checks: %v", seriesID, attempt, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
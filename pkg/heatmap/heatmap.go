@@ -0,0 +1,365 @@
+// Package heatmap renders heatmaps of player positions recorded in events archives.
+//
+// Positions are read from the player states carried by events, both in the actor and target
+// of an event and in the series state. Heatmaps are rendered to PNG with the standard image
+// libraries only, over a bounding box of map coordinates and optionally over a background
+// image of the map.
+package heatmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"iter"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+)
+
+// Point is a position of a player during a game.
+type Point struct {
+	GameNumber int           // GameNumber is the number of the game in the series.
+	GameTime   time.Duration // GameTime is the time elapsed since the start of the game.
+	PlayerID   string        // PlayerID is the unique identifier of the player.
+	Player     string        // Player is the name of the player, if known.
+	TeamID     string        // TeamID is the unique identifier of the team of the player, if known.
+	Team       string        // Team is the name of the team of the player, if known.
+	X          float64       // X is the horizontal map coordinate.
+	Y          float64       // Y is the vertical map coordinate.
+}
+
+// Bounds is a rectangle of map coordinates.
+type Bounds struct {
+	MinX, MinY, MaxX, MaxY float64
+}
+
+// Options configures the rendering of a heatmap.
+type Options struct {
+	Width      int         // Width is the width of the image in pixels.
+	Height     int         // Height is the height of the image in pixels.
+	Radius     int         // Radius is the radius in pixels over which each position is spread.
+	Background image.Image // Background is drawn below the heatmap, scaled to the image, if set.
+}
+
+// position is the subset of a player state holding its position.
+type position struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Position *struct {
+		X float64 `json:"x"`
+		Y float64 `json:"y"`
+	} `json:"position"`
+}
+
+// seriesPositions is the subset of a series state or state delta holding player positions.
+type seriesPositions struct {
+	Games []struct {
+		Teams []struct {
+			ID      string     `json:"id"`
+			Name    string     `json:"name"`
+			Players []position `json:"players"`
+		} `json:"teams"`
+	} `json:"games"`
+}
+
+// Positions returns an iterator over the positions of the players during the games of a series.
+//
+// A position is only yielded when it differs from the previous position of the player, so that
+// positions repeated in several states of the same event are counted once.
+//
+// Parameters:
+//   - seq: An iterator over the events of the series, in order.
+//
+// Returns:
+//   - iter.Seq2[Point, error]: An iterator over the positions. The iteration stops after the first error.
+func Positions(seq iter.Seq2[events.Envelope, error]) iter.Seq2[Point, error] {
+	return func(yield func(Point, error) bool) {
+		teamNames := map[string]string{}
+		playerTeams := map[string]string{}
+		names := map[string]string{}
+		last := map[string][2]float64{}
+		games := 0
+		inGame := false
+		var gameStart time.Time
+
+		for env, err := range seq {
+			if err != nil {
+				yield(Point{}, err)
+				return
+			}
+			switch env.Type {
+			case "series-started-game":
+				games++
+				inGame = true
+				gameStart = env.OccurredAt
+				clear(last)
+			case "series-ended-game":
+				inGame = false
+			}
+
+			var found []position
+			for _, raw := range []json.RawMessage{env.SeriesState, env.SeriesStateDelta} {
+				if len(raw) == 0 {
+					continue
+				}
+				var s seriesPositions
+				if err := json.Unmarshal(raw, &s); err != nil {
+					yield(Point{}, fmt.Errorf("error decoding series state of event %s: %v", env.ID, err))
+					return
+				}
+				for _, g := range s.Games {
+					for _, t := range g.Teams {
+						if t.Name != "" {
+							teamNames[t.ID] = t.Name
+						}
+						for _, p := range t.Players {
+							playerTeams[p.ID] = t.ID
+							found = append(found, p)
+						}
+					}
+				}
+			}
+			for _, e := range []events.Entity{env.Actor, env.Target} {
+				if e.Type != "player" {
+					continue
+				}
+				for _, raw := range []json.RawMessage{e.State, e.StateDelta} {
+					var p position
+					if len(raw) > 0 && json.Unmarshal(raw, &p) == nil {
+						p.ID = e.ID
+						found = append(found, p)
+					}
+				}
+			}
+
+			for _, p := range found {
+				if p.Name != "" {
+					names[p.ID] = p.Name
+				}
+				if !inGame || p.Position == nil {
+					continue
+				}
+				xy := [2]float64{p.Position.X, p.Position.Y}
+				if prev, ok := last[p.ID]; ok && prev == xy {
+					continue
+				}
+				last[p.ID] = xy
+				teamID := playerTeams[p.ID]
+				point := Point{
+					GameNumber: games,
+					GameTime:   env.OccurredAt.Sub(gameStart),
+					PlayerID:   p.ID,
+					Player:     names[p.ID],
+					TeamID:     teamID,
+					Team:       teamNames[teamID],
+					X:          xy[0],
+					Y:          xy[1],
+				}
+				if !yield(point, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ParseBounds parses a bounding box written as "minX,minY,maxX,maxY".
+func ParseBounds(value string) (Bounds, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return Bounds{}, fmt.Errorf("invalid bounds %q: expected minX,minY,maxX,maxY", value)
+	}
+	var v [4]float64
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return Bounds{}, fmt.Errorf("invalid bounds %q: %v", value, err)
+		}
+		v[i] = f
+	}
+	b := Bounds{MinX: v[0], MinY: v[1], MaxX: v[2], MaxY: v[3]}
+	if b.MaxX <= b.MinX || b.MaxY <= b.MinY {
+		return Bounds{}, fmt.Errorf("invalid bounds %q: the maximum must be greater than the minimum", value)
+	}
+	return b, nil
+}
+
+// BoundsOf returns the smallest bounding box holding all the points, with a 5% margin.
+func BoundsOf(points []Point) Bounds {
+	if len(points) == 0 {
+		return Bounds{MaxX: 1, MaxY: 1}
+	}
+	b := Bounds{MinX: math.Inf(1), MinY: math.Inf(1), MaxX: math.Inf(-1), MaxY: math.Inf(-1)}
+	for _, p := range points {
+		b.MinX, b.MaxX = math.Min(b.MinX, p.X), math.Max(b.MaxX, p.X)
+		b.MinY, b.MaxY = math.Min(b.MinY, p.Y), math.Max(b.MaxY, p.Y)
+	}
+	marginX := math.Max((b.MaxX-b.MinX)*0.05, 1)
+	marginY := math.Max((b.MaxY-b.MinY)*0.05, 1)
+	return Bounds{MinX: b.MinX - marginX, MinY: b.MinY - marginY, MaxX: b.MaxX + marginX, MaxY: b.MaxY + marginY}
+}
+
+// Render renders a heatmap of positions.
+//
+// Positions outside of the bounds are ignored; the bounds themselves are included. The density
+// of positions is spread with a Gaussian kernel of the given radius, normalized by its maximum
+// and mapped from transparent blue for low densities to opaque red for the highest ones. The
+// vertical axis points up, as in map coordinates.
+//
+// Parameters:
+//   - points: The positions to render.
+//   - b: The map coordinates covered by the image.
+//   - opts: The size of the image, the radius of the kernel and the optional background.
+//
+// Returns:
+//   - *image.RGBA: The rendered heatmap.
+func Render(points []Point, b Bounds, opts Options) *image.RGBA {
+	w, h := opts.Width, opts.Height
+	density := make([]float64, w*h)
+	for _, p := range points {
+		if p.X < b.MinX || p.X > b.MaxX || p.Y < b.MinY || p.Y > b.MaxY {
+			continue
+		}
+		// Positions on the maximum edges of the bounds fall in the last pixel.
+		x := min(int((p.X-b.MinX)/(b.MaxX-b.MinX)*float64(w)), w-1)
+		y := max(h-1-int((p.Y-b.MinY)/(b.MaxY-b.MinY)*float64(h)), 0)
+		density[y*w+x]++
+	}
+	density = blur(density, w, h, opts.Radius)
+
+	max := 0.0
+	for _, d := range density {
+		max = math.Max(max, d)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{R: 16, G: 16, B: 24, A: 255}), image.Point{}, draw.Src)
+	if opts.Background != nil {
+		drawScaled(img, opts.Background)
+	}
+	if max == 0 {
+		return img
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if d := density[y*w+x]; d > 0 {
+				draw.Draw(img, image.Rect(x, y, x+1, y+1), image.NewUniform(palette(d/max)), image.Point{}, draw.Over)
+			}
+		}
+	}
+	return img
+}
+
+// blur spreads a density grid with a separable Gaussian kernel.
+func blur(density []float64, w, h, radius int) []float64 {
+	if radius <= 0 {
+		return density
+	}
+	sigma := float64(radius) / 2
+	kernel := make([]float64, 2*radius+1)
+	for i := range kernel {
+		d := float64(i - radius)
+		kernel[i] = math.Exp(-d * d / (2 * sigma * sigma))
+	}
+
+	tmp := make([]float64, len(density))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if v := density[y*w+x]; v > 0 {
+				for k, weight := range kernel {
+					if xx := x + k - radius; xx >= 0 && xx < w {
+						tmp[y*w+xx] += v * weight
+					}
+				}
+			}
+		}
+	}
+	out := make([]float64, len(density))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if v := tmp[y*w+x]; v > 0 {
+				for k, weight := range kernel {
+					if yy := y + k - radius; yy >= 0 && yy < h {
+						out[yy*w+x] += v * weight
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// palette maps a normalized density to a color, from transparent blue to opaque red.
+func palette(v float64) color.NRGBA {
+	stops := []struct {
+		at      float64
+		r, g, b float64
+	}{
+		{0, 0, 0, 255},
+		{0.35, 0, 255, 255},
+		{0.6, 0, 255, 0},
+		{0.8, 255, 255, 0},
+		{1, 255, 0, 0},
+	}
+	v = math.Max(0, math.Min(1, v))
+	for i := 1; i < len(stops); i++ {
+		if v <= stops[i].at {
+			a, b := stops[i-1], stops[i]
+			t := (v - a.at) / (b.at - a.at)
+			return color.NRGBA{
+				R: uint8(a.r + (b.r-a.r)*t),
+				G: uint8(a.g + (b.g-a.g)*t),
+				B: uint8(a.b + (b.b-a.b)*t),
+				A: uint8(64 + 176*math.Sqrt(v)),
+			}
+		}
+	}
+	return color.NRGBA{R: 255, A: 240}
+}
+
+// drawScaled draws an image scaled to the destination with nearest neighbour sampling.
+func drawScaled(dst *image.RGBA, src image.Image) {
+	sb := src.Bounds()
+	db := dst.Bounds()
+	for y := db.Min.Y; y < db.Max.Y; y++ {
+		sy := sb.Min.Y + (y-db.Min.Y)*sb.Dy()/db.Dy()
+		for x := db.Min.X; x < db.Max.X; x++ {
+			sx := sb.Min.X + (x-db.Min.X)*sb.Dx()/db.Dx()
+			dst.Set(x, y, src.At(sx, sy))
+		}
+	}
+}
+
+// ReadImage reads a PNG image, e.g. a map used as background.
+func ReadImage(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening image: %v", err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding image %s: %v", path, err)
+	}
+	return img, nil
+}
+
+// WritePNG writes an image to a PNG file.
+func WritePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating file: %v", err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, img); err != nil {
+		return fmt.Errorf("error encoding PNG: %v", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,68 @@
+package heatmap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+)
+
+const testEvents = `{"id":"tx-1","sequenceNumber":1,"occurredAt":"2024-05-10T12:00:00Z","seriesId":"2700000","events":[{"id":"ev-1","type":"series-started-game","target":{"type":"game","id":"g1"},"seriesState":{"games":[{"id":"g1","teams":[{"id":"t1","name":"Team 1","players":[{"id":"p1","name":"Player 1","position":{"x":100,"y":200}}]}]}]}}]}
+{"id":"tx-2","sequenceNumber":2,"occurredAt":"2024-05-10T12:01:00Z","seriesId":"2700000","events":[{"id":"ev-2","type":"player-killed-player","actor":{"type":"player","id":"p1","stateDelta":{"position":{"x":150,"y":250}}},"seriesStateDelta":{"games":[{"id":"g1","teams":[{"id":"t1","players":[{"id":"p1","position":{"x":150,"y":250}}]}]}]}}]}
+{"id":"tx-3","sequenceNumber":3,"occurredAt":"2024-05-10T12:02:00Z","seriesId":"2700000","events":[{"id":"ev-3","type":"series-ended-game","target":{"type":"game","id":"g1"}}]}
+{"id":"tx-4","sequenceNumber":4,"occurredAt":"2024-05-10T12:03:00Z","seriesId":"2700000","events":[{"id":"ev-4","type":"player-moved","actor":{"type":"player","id":"p1","stateDelta":{"position":{"x":0,"y":0}}}}]}
+`
+
+func TestPositions(t *testing.T) {
+	var points []Point
+	for p, err := range Positions(events.Flatten(events.ReadTransactions(strings.NewReader(testEvents)))) {
+		if err != nil {
+			t.Fatalf("Failed to read positions: %v", err)
+		}
+		points = append(points, p)
+	}
+	if len(points) != 2 {
+		t.Fatalf("Expected 2 distinct positions during the game, but got %d", len(points))
+	}
+	p := points[1]
+	if p.X != 150 || p.Y != 250 || p.Team != "Team 1" || p.Player != "Player 1" || p.GameTime != time.Minute || p.GameNumber != 1 {
+		t.Fatalf("Unexpected position %+v", p)
+	}
+}
+
+func TestRender(t *testing.T) {
+	b, err := ParseBounds("0,0,100,100")
+	if err != nil {
+		t.Fatalf("Failed to parse bounds: %v", err)
+	}
+	if _, err := ParseBounds("10,0,0,10"); err == nil {
+		t.Fatalf("Expected an error for inverted bounds, but got none")
+	}
+
+	img := Render([]Point{{X: 25, Y: 75}, {X: 25, Y: 75}, {X: 500, Y: 500}}, b, Options{Width: 100, Height: 100, Radius: 4})
+	hot := img.RGBAAt(25, 24)
+	cold := img.RGBAAt(90, 90)
+	if hot.R <= cold.R {
+		t.Fatalf("Expected the position to be rendered near the top left, but got %v and %v", hot, cold)
+	}
+
+	// Positions on the maximum edges of the bounds are kept, in the top right pixel.
+	edge := Render([]Point{{X: 100, Y: 100}}, b, Options{Width: 100, Height: 100, Radius: 2})
+	if corner, opposite := edge.RGBAAt(99, 0), edge.RGBAAt(0, 99); corner.R <= opposite.R {
+		t.Fatalf("Expected the position on the edge to be rendered, but got %v and %v", corner, opposite)
+	}
+
+	path := filepath.Join(t.TempDir(), "heatmap.png")
+	if err := WritePNG(path, img); err != nil {
+		t.Fatalf("Failed to write PNG: %v", err)
+	}
+	if _, err := ReadImage(path); err != nil {
+		t.Fatalf("Failed to read PNG back: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Expected PNG to exist, but got %v", err)
+	}
+}
@@ -0,0 +1,283 @@
+// Package library keeps track of the files downloaded from the GRID file-download API.
+//
+// The library is a JSON manifest stored next to the configuration file. Every
+// downloaded file is recorded with the metadata of its series, its location on
+// disk, its size, its SHA-256 hash and the time it was downloaded, so that the
+// application can tell which series are already available locally.
+//
+// Several processes, such as the user interface and a headless wait, may record
+// downloads at the same time: each recording merges into the manifest on disk
+// while holding a lock file next to it.
+package library
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/config"
+)
+
+// manifestName is the file name of the manifest inside the configuration directory.
+const manifestName = "library.json"
+
+const (
+	// lockTimeout is the maximum time to wait for the lock of the manifest.
+	lockTimeout = 10 * time.Second
+
+	// staleLock is the age after which a lock left by a crashed process is removed.
+	staleLock = 30 * time.Second
+)
+
+// Series holds the metadata of the series a downloaded file belongs to.
+type Series struct {
+	ID         string   `json:"id"`                   // ID is the GRID series ID.
+	TitleID    string   `json:"titleId,omitempty"`    // TitleID is the GRID title ID of the series.
+	StartTime  string   `json:"startTime,omitempty"`  // StartTime is the scheduled start time in RFC3339 format.
+	Tournament string   `json:"tournament,omitempty"` // Tournament is the name of the tournament.
+	Teams      []string `json:"teams,omitempty"`      // Teams are the names of the teams playing the series.
+}
+
+// Entry represents a single downloaded file.
+type Entry struct {
+	Series       Series    `json:"series"`       // Series is the metadata of the series the file belongs to.
+	FileID       string    `json:"fileId"`       // FileID identifies the file within the series (e.g. the events file or a game number).
+	Path         string    `json:"path"`         // Path is the absolute path of the file on disk.
+	Size         int64     `json:"size"`         // Size is the size of the file in bytes.
+	SHA256       string    `json:"sha256"`       // SHA256 is the hex encoded SHA-256 hash of the file.
+	DownloadedAt time.Time `json:"downloadedAt"` // DownloadedAt is the time the file was downloaded.
+}
+
+// Exists reports whether the file of the entry is still present on disk.
+func (e Entry) Exists() bool {
+	_, err := os.Stat(e.Path)
+	return err == nil
+}
+
+// Library is the set of downloaded files backed by a JSON manifest.
+//
+// A nil *Library is valid and behaves as an empty library that cannot record entries.
+type Library struct {
+	mu      sync.Mutex
+	path    string
+	Entries []Entry `json:"entries"`
+}
+
+// Open loads the library from the manifest in the configuration directory.
+//
+// Returns:
+//   - *Library: The loaded library, empty if the manifest does not exist yet.
+//   - error: An error if the configuration directory cannot be determined or the manifest cannot be read.
+func Open() (*Library, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return nil, fmt.Errorf("error getting configuration directory: %v", err)
+	}
+	return OpenFile(filepath.Join(dir, manifestName))
+}
+
+// OpenFile loads the library from the manifest at the given path.
+//
+// Parameters:
+//   - path: The path of the manifest file. It is created on the first recorded entry if it does not exist.
+//
+// Returns:
+//   - *Library: The loaded library, empty if the manifest does not exist yet.
+//   - error: An error if the manifest exists but cannot be read or decoded.
+func OpenFile(path string) (*Library, error) {
+	l := &Library{path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading library manifest: %v", err)
+	}
+	if err := json.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("error decoding library manifest: %v", err)
+	}
+	return l, nil
+}
+
+// Find returns the entry recorded for a file of a series.
+//
+// Entries whose file has been removed from disk are ignored.
+//
+// Parameters:
+//   - seriesID: The ID of the series.
+//   - fileID: The identifier of the file within the series.
+//
+// Returns:
+//   - Entry: The recorded entry, if any.
+//   - bool: Whether an entry with an existing file was found.
+func (l *Library) Find(seriesID, fileID string) (Entry, bool) {
+	if l == nil {
+		return Entry{}, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, e := range l.Entries {
+		if e.Series.ID == seriesID && e.FileID == fileID && e.Exists() {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Series returns all entries with an existing file recorded for a series.
+func (l *Library) Series(seriesID string) []Entry {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var entries []Entry
+	for _, e := range l.Entries {
+		if e.Series.ID == seriesID && e.Exists() {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// Has reports whether any file of the series has been downloaded.
+func (l *Library) Has(seriesID string) bool {
+	return len(l.Series(seriesID)) > 0
+}
+
+// Record adds a downloaded file to the library and saves the manifest.
+//
+// The size and hash of the file are computed from its content. An existing entry for
+// the same series and file is replaced. The manifest is read again under a lock before
+// it is saved, so that entries recorded by other processes since the library was opened
+// are kept, and loaded into the library.
+//
+// Parameters:
+//   - series: The metadata of the series the file belongs to.
+//   - fileID: The identifier of the file within the series.
+//   - path: The path of the downloaded file.
+//
+// Returns:
+//   - Entry: The recorded entry.
+//   - error: An error if the file cannot be hashed or the manifest cannot be saved.
+func (l *Library) Record(series Series, fileID, path string) (Entry, error) {
+	if l == nil {
+		return Entry{}, fmt.Errorf("library is not available")
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return Entry{}, fmt.Errorf("error resolving file path: %v", err)
+	}
+	size, sum, err := hashFile(absPath)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry := Entry{
+		Series:       series,
+		FileID:       fileID,
+		Path:         absPath,
+		Size:         size,
+		SHA256:       sum,
+		DownloadedAt: time.Now().UTC(),
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	unlock, err := lockFile(l.path + ".lock")
+	if err != nil {
+		return Entry{}, err
+	}
+	defer unlock()
+
+	current, err := OpenFile(l.path)
+	if err != nil {
+		return Entry{}, err
+	}
+	l.Entries = current.Entries
+	replaced := false
+	for i, e := range l.Entries {
+		if e.Series.ID == series.ID && e.FileID == fileID {
+			l.Entries[i] = entry
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		l.Entries = append(l.Entries, entry)
+	}
+	return entry, l.save()
+}
+
+// lockFile acquires a lock shared between processes by creating a lock file exclusively.
+//
+// A lock file older than staleLock is considered left by a crashed process and removed.
+//
+// Parameters:
+//   - path: The path of the lock file.
+//
+// Returns:
+//   - func(): A function releasing the lock.
+//   - error: An error if the lock cannot be acquired within lockTimeout.
+func lockFile(path string) (func(), error) {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("error locking library manifest: %v", err)
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLock {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("error locking library manifest: %s is held by another process", path)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// save writes the manifest to disk, replacing the previous one atomically.
+//
+// The caller must hold l.mu.
+func (l *Library) save() error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding library manifest: %v", err)
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("error writing library manifest: %v", err)
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		return fmt.Errorf("error saving library manifest: %v", err)
+	}
+	return nil
+}
+
+// hashFile returns the size and the hex encoded SHA-256 hash of a file.
+func hashFile(path string) (int64, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, "", fmt.Errorf("error opening downloaded file: %v", err)
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, file)
+	if err != nil {
+		return 0, "", fmt.Errorf("error hashing downloaded file: %v", err)
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
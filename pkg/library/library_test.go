@@ -0,0 +1,92 @@
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordAndFind(t *testing.T) {
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "library.json")
+	file := filepath.Join(dir, "2620066.zip")
+	if err := os.WriteFile(file, []byte("zip content"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	lib, err := OpenFile(manifest)
+	if err != nil {
+		t.Fatalf("Failed to open library: %v", err)
+	}
+	series := Series{ID: "2620066", TitleID: "3", Teams: []string{"Team 1", "Team 2"}}
+	if _, err := lib.Record(series, "events-grid-compressed", file); err != nil {
+		t.Fatalf("Failed to record entry: %v", err)
+	}
+	if _, err := lib.Record(series, "events-grid-compressed", file); err != nil {
+		t.Fatalf("Failed to record entry again: %v", err)
+	}
+
+	reopened, err := OpenFile(manifest)
+	if err != nil {
+		t.Fatalf("Failed to reopen library: %v", err)
+	}
+	if len(reopened.Entries) != 1 {
+		t.Fatalf("Expected 1 entry, but got %d", len(reopened.Entries))
+	}
+	entry, ok := reopened.Find("2620066", "events-grid-compressed")
+	if !ok {
+		t.Fatalf("Expected entry to be found")
+	}
+	if entry.Size != int64(len("zip content")) {
+		t.Fatalf("Expected size %d, but got %d", len("zip content"), entry.Size)
+	}
+	if entry.SHA256 == "" {
+		t.Fatalf("Expected non-empty hash")
+	}
+
+	if err := os.Remove(file); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	if reopened.Has("2620066") {
+		t.Fatalf("Expected removed file not to be reported as downloaded")
+	}
+}
+
+func TestRecordMerge(t *testing.T) {
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "library.json")
+	file := filepath.Join(dir, "2620066.zip")
+	if err := os.WriteFile(file, []byte("zip content"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	// Two processes open the library before either records a download.
+	first, err := OpenFile(manifest)
+	if err != nil {
+		t.Fatalf("Failed to open library: %v", err)
+	}
+	second, err := OpenFile(manifest)
+	if err != nil {
+		t.Fatalf("Failed to open library: %v", err)
+	}
+	if _, err := first.Record(Series{ID: "2620066"}, "events-grid-compressed", file); err != nil {
+		t.Fatalf("Failed to record entry: %v", err)
+	}
+	if _, err := second.Record(Series{ID: "2620066"}, "1", file); err != nil {
+		t.Fatalf("Failed to record entry: %v", err)
+	}
+
+	reopened, err := OpenFile(manifest)
+	if err != nil {
+		t.Fatalf("Failed to reopen library: %v", err)
+	}
+	if len(reopened.Entries) != 2 {
+		t.Fatalf("Expected the entries of both processes, but got %d", len(reopened.Entries))
+	}
+	if _, ok := second.Find("2620066", "events-grid-compressed"); !ok {
+		t.Fatalf("Expected the entry of the other process to be loaded")
+	}
+	if _, err := os.Stat(manifest + ".lock"); !os.IsNotExist(err) {
+		t.Fatalf("Expected the lock to be released, but got %v", err)
+	}
+}
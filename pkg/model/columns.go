@@ -0,0 +1,125 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/config"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/export"
+)
+
+// columnChoice is a column listed on the column screen.
+type columnChoice struct {
+	column  export.Column // column is the column of the series table.
+	visible bool          // visible reports whether the column is displayed and exported.
+}
+
+// openColumns displays the columns of the series table for the user to show, hide and reorder them.
+//
+// Returns:
+//   - tea.Model: The updated model.
+//   - tea.Cmd: A command to be executed, if any.
+func (m *Model) openColumns() (tea.Model, tea.Cmd) {
+	m.CurrentState = ChooseColumns
+	m.ColumnCursor = 0
+	return m, tea.ClearScreen
+}
+
+// columnChoices returns the visible columns in their order, followed by the hidden columns.
+func (m *Model) columnChoices() []columnChoice {
+	choices := make([]columnChoice, 0, len(export.SeriesColumns))
+	visible := map[string]bool{}
+	for _, c := range m.Columns {
+		choices = append(choices, columnChoice{column: c, visible: true})
+		visible[c.Key] = true
+	}
+	for _, c := range export.SeriesColumns {
+		if !visible[c.Key] {
+			choices = append(choices, columnChoice{column: c})
+		}
+	}
+	return choices
+}
+
+// handleColumnsKey handles the keys of the column screen.
+//
+// Up and down move the cursor, space shows or hides the column under the cursor, shift+up and
+// shift+down move a visible column left or right in the table, 'w' saves the columns to the
+// configuration file, and Enter or Esc go back to the series table with the chosen columns.
+//
+// Parameters:
+//   - msg: The key message to be handled.
+//
+// Returns:
+//   - tea.Model: The updated model.
+//   - tea.Cmd: A command to be executed, if any.
+func (m *Model) handleColumnsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	choices := m.columnChoices()
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "up", "k":
+		if m.ColumnCursor > 0 {
+			m.ColumnCursor--
+		}
+	case "down", "j":
+		if m.ColumnCursor < len(choices)-1 {
+			m.ColumnCursor++
+		}
+	case " ":
+		choice := choices[m.ColumnCursor]
+		if !choice.visible {
+			m.Columns = append(m.Columns, choice.column)
+			m.ColumnCursor = len(m.Columns) - 1
+		} else if len(m.Columns) == 1 {
+			m.StatusMsg = "At least one column must be displayed."
+		} else {
+			m.Columns = append(m.Columns[:m.ColumnCursor:m.ColumnCursor], m.Columns[m.ColumnCursor+1:]...)
+			if m.ColumnCursor >= len(m.Columns) {
+				m.ColumnCursor = len(m.Columns) - 1
+			}
+		}
+	case "shift+up", "K":
+		if i := m.ColumnCursor; i > 0 && i < len(m.Columns) {
+			m.Columns[i-1], m.Columns[i] = m.Columns[i], m.Columns[i-1]
+			m.ColumnCursor--
+		}
+	case "shift+down", "J":
+		if i := m.ColumnCursor; i < len(m.Columns)-1 {
+			m.Columns[i], m.Columns[i+1] = m.Columns[i+1], m.Columns[i]
+			m.ColumnCursor++
+		}
+	case "w":
+		if err := config.SetSeriesColumns(export.ColumnKeys(m.Columns)); err != nil {
+			m.StatusMsg = err.Error()
+		} else {
+			m.StatusMsg = "Columns saved to the configuration."
+		}
+	case "enter", "esc", "backspace":
+		m.CurrentState = ShowTable
+		m.setSeriesTable()
+		return m, tea.ClearScreen
+	}
+	return m, nil
+}
+
+// columnsView renders the column screen.
+func (m Model) columnsView() string {
+	var b strings.Builder
+	b.WriteString("Columns of the series table\n\n")
+	for i, choice := range m.columnChoices() {
+		cursor := "  "
+		if i == m.ColumnCursor {
+			cursor = "> "
+		}
+		mark := "[ ]"
+		if choice.visible {
+			mark = "[x]"
+		}
+		fmt.Fprintf(&b, "%s%s %-20s %s\n", cursor, mark, choice.column.Title, choice.column.Key)
+	}
+	return BaseStyle.Render(strings.TrimSuffix(b.String(), "\n")) +
+		"\nPress Space to show or hide a column, Shift+Up and Shift+Down to move it, 'w' to save the columns, or Enter to go back." +
+		m.statusLine()
+}
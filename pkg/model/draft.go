@@ -0,0 +1,75 @@
+package model
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/draft"
+)
+
+// draftCmd extracts the draft of a series from its events archive.
+//
+// Parameters:
+//   - path: The path of the events archive of the series.
+//
+// Returns:
+//   - tea.Cmd: A command that returns the *draft.Draft, or an error message.
+func draftCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		d, err := draft.ExtractArchive(path)
+		if err != nil {
+			return fmt.Sprintf("Error extracting draft: %v", err)
+		}
+		return d
+	}
+}
+
+// openDraft starts loading the draft of the series selected in the table.
+//
+// The events archive of the series must be in the download library, otherwise a status
+// message is displayed below the table.
+//
+// Returns:
+//   - tea.Model: The updated model.
+//   - tea.Cmd: A command to be executed, if any.
+func (m *Model) openDraft() (tea.Model, tea.Cmd) {
+	path, ok := m.selectedArchive()
+	if !ok {
+		return m, nil
+	}
+	m.CurrentState = ShowDraft
+	m.SelectedID = m.selectedSeriesID()
+	m.Loading = true
+	return m, tea.Batch(tea.ClearScreen, draftCmd(path), m.Spinner.Tick)
+}
+
+// handleDraftMsg displays a loaded draft.
+func (m *Model) handleDraftMsg(d *draft.Draft) (tea.Model, tea.Cmd) {
+	m.Loading = false
+	m.Draft = d
+	rows := d.Rows()
+	m.DraftTable = newStyledTable(columnsFor(draft.Headers, rows), toTableRows(rows), 15)
+	return m, nil
+}
+
+// exportDraft exports the draft table.
+func (m *Model) exportDraft() tea.Cmd {
+	if m.Draft == nil {
+		return nil
+	}
+	return m.exportTable(draft.Headers, m.Draft.Rows())
+}
+
+// draftView renders the draft screen.
+func (m Model) draftView() string {
+	if m.Loading || m.Draft == nil {
+		return BaseStyle.Render(fmt.Sprintf("\n\n   %s Reading events, please wait...  \n\n", m.Spinner.View()))
+	}
+	if len(m.Draft.Actions) == 0 {
+		return BaseStyle.Render(fmt.Sprintf("No picks or bans found in the events of series %s.", m.SelectedID)) +
+			"\nPress Esc to go back."
+	}
+	return fmt.Sprintf("Series %s: %d picks and bans\n", m.SelectedID, len(m.Draft.Actions)) +
+		BaseStyle.Render(m.DraftTable.View()) +
+		"\nPress 'e' to export, or Esc to go back." + m.statusLine()
+}
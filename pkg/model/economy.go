@@ -0,0 +1,94 @@
+package model
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/economy"
+)
+
+// economyCmd extracts the economy timeline of a series from its events archive.
+//
+// Parameters:
+//   - path: The path of the events archive of the series.
+//   - titleID: The title of the series.
+//
+// Returns:
+//   - tea.Cmd: A command that returns the *economy.Economy, or an error message.
+func economyCmd(path, titleID string) tea.Cmd {
+	return func() tea.Msg {
+		e, err := economy.ExtractArchive(path, titleID)
+		if err != nil {
+			return fmt.Sprintf("Error extracting economy: %v", err)
+		}
+		return e
+	}
+}
+
+// openEconomy starts loading the economy timeline of the series selected in the table.
+//
+// Economy timelines are only available for CS2 and Valorant series whose events archive is
+// in the download library, otherwise a status message is displayed below the table.
+//
+// Returns:
+//   - tea.Model: The updated model.
+//   - tea.Cmd: A command to be executed, if any.
+func (m *Model) openEconomy() (tea.Model, tea.Cmd) {
+	if !economy.Supported(m.TitleID) {
+		m.StatusMsg = "Economy timelines are only available for CS2 and Valorant series."
+		return m, nil
+	}
+	path, ok := m.selectedArchive()
+	if !ok {
+		return m, nil
+	}
+	m.CurrentState = ShowEconomy
+	m.SelectedID = m.selectedSeriesID()
+	m.Loading = true
+	m.EconomyRounds = false
+	return m, tea.Batch(tea.ClearScreen, economyCmd(path, m.TitleID), m.Spinner.Tick)
+}
+
+// handleEconomyMsg displays a loaded economy timeline.
+func (m *Model) handleEconomyMsg(e *economy.Economy) (tea.Model, tea.Cmd) {
+	m.Loading = false
+	m.Economy = e
+	m.setEconomyTable()
+	return m, nil
+}
+
+// setEconomyTable fills the economy table with the buy summary or the round by round rows.
+func (m *Model) setEconomyTable() {
+	headers, rows := m.economyRows()
+	m.EconomyTable = newStyledTable(columnsFor(headers, rows), toTableRows(rows), 15)
+}
+
+// economyRows returns the headers and rows of the economy table currently displayed.
+func (m *Model) economyRows() ([]string, [][]string) {
+	if m.EconomyRounds {
+		return economy.Headers, m.Economy.Rows()
+	}
+	return economy.SummaryHeaders, m.Economy.SummaryRows()
+}
+
+// exportEconomy exports the economy table currently displayed.
+func (m *Model) exportEconomy() tea.Cmd {
+	if m.Economy == nil {
+		return nil
+	}
+	return m.exportTable(m.economyRows())
+}
+
+// economyView renders the economy screen.
+func (m Model) economyView() string {
+	if m.Loading || m.Economy == nil {
+		return BaseStyle.Render(fmt.Sprintf("\n\n   %s Reading events, please wait...  \n\n", m.Spinner.View()))
+	}
+	view := "rounds"
+	if m.EconomyRounds {
+		view = "the buy summary"
+	}
+	return fmt.Sprintf("Series %s: %d rounds\n", m.SelectedID, len(m.Economy.Rounds)) +
+		BaseStyle.Render(m.EconomyTable.View()) +
+		fmt.Sprintf("\nPress 'r' to show %s, 'e' to export, or Esc to go back.", view) + m.statusLine()
+}
@@ -0,0 +1,243 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/config"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/export"
+	"github.com/sqweek/dialog"
+)
+
+// exportFormats are the export formats offered by the TUI, with the keys selecting them.
+var exportFormats = []struct {
+	key        string
+	format     export.Format
+	label      string
+	seriesOnly bool
+}{
+	{"c", export.CSV, "CSV", false},
+	{"j", export.JSON, "JSON", false},
+	{"n", export.NDJSON, "NDJSON", false},
+	{"x", export.XLSX, "Excel", false},
+	{"i", export.ICS, "iCalendar", true},
+	{"m", export.Markdown, "a Markdown report", true},
+	{"h", export.HTML, "an HTML report", true},
+}
+
+// exportMsg reports the result of an export started from the TUI.
+type exportMsg struct {
+	path   string // path is the path of the written file, empty if the export was canceled.
+	err    error  // err is the error that occurred while exporting, if any.
+	prompt bool   // prompt indicates that the save dialog is unavailable and the path must be typed.
+}
+
+// guiAvailable reports whether a save dialog can be opened, that is on Windows and macOS, or
+// on other systems when a graphical display is available.
+func guiAvailable() bool {
+	switch runtime.GOOS {
+	case "windows", "darwin":
+		return true
+	}
+	return os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+}
+
+// exportTable starts the export of a table by asking for its format.
+//
+// Parameters:
+//   - headers: The headers of the table.
+//   - rows: The rows of the table.
+//
+// Returns:
+//   - tea.Cmd: Always nil, the export continues when a format is chosen.
+func (m *Model) exportTable(headers []string, rows [][]string) tea.Cmd {
+	m.ExportHeaders = headers
+	m.ExportRows = rows
+	m.ExportSeries = nil
+	m.ExportChoosing = true
+	return nil
+}
+
+// exportSeries starts the export of the series table by asking for its format. The series are
+// exported in the columns of the table, except in JSON and NDJSON where, unlike the other
+// tables, they are exported with all their fields.
+//
+// Returns:
+//   - tea.Cmd: Always nil, the export continues when a format is chosen.
+func (m *Model) exportSeries() tea.Cmd {
+	m.exportTable(export.Headers(m.Columns), export.ColumnRows(m.Series, m.Columns))
+	m.ExportSeries = m.Series
+	return nil
+}
+
+// exportWriter returns the exporter of the pending export in a format. Reports are rendered
+// with the template configured for their format, if any.
+func (m *Model) exportWriter(format export.Format) func(w io.Writer) error {
+	headers, rows, series, columns := m.ExportHeaders, m.ExportRows, m.ExportSeries, m.Columns
+	return func(w io.Writer) error {
+		if format == export.Markdown || format == export.HTML {
+			tmpl, err := export.ReadTemplate(config.GetReportTemplate(export.TemplateName(format)))
+			if err != nil {
+				return err
+			}
+			return export.WriteReport(w, series, format, export.ReportOptions{Template: tmpl})
+		}
+		if series != nil {
+			return export.ExportSeriesColumns(w, series, columns, format)
+		}
+		return export.ExportTable(w, headers, rows, format)
+	}
+}
+
+// handleExportFormatKey chooses the format of the pending export, then where to save it.
+//
+// The file is chosen with a save dialog when a graphical display is available, and typed in
+// a prompt of the TUI otherwise, e.g. over SSH on a headless server.
+//
+// Parameters:
+//   - msg: A tea.KeyMsg representing the key message to be handled.
+//
+// Returns:
+//   - tea.Model: The updated model.
+//   - tea.Cmd: A command that opens the save dialog and writes the file, or nil if the path is prompted.
+func (m *Model) handleExportFormatKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "backspace":
+		m.ExportChoosing = false
+		return m.handleExportMsg(exportMsg{})
+	}
+	for _, f := range exportFormats {
+		if msg.String() != f.key || (f.seriesOnly && m.ExportSeries == nil) {
+			continue
+		}
+		m.ExportChoosing = false
+		m.ExportFormat = f.format
+		if !guiAvailable() {
+			m.openExportPrompt()
+			return m, tea.ClearScreen
+		}
+		return m, saveDialogCmd(f.format, m.exportWriter(f.format))
+	}
+	return m, nil
+}
+
+// saveDialogCmd asks for the file to export to with a save dialog and writes it.
+//
+// Parameters:
+//   - format: The format of the export.
+//   - write: The exporter writing the data to the file.
+//
+// Returns:
+//   - tea.Cmd: A command that returns an exportMsg.
+func saveDialogCmd(format export.Format, write func(w io.Writer) error) tea.Cmd {
+	return func() tea.Msg {
+		name := strings.ToUpper(string(format))
+		path, err := dialog.File().Title("Save "+name+" File").Filter(name+" files", string(format)).Save()
+		if errors.Is(err, dialog.ErrCancelled) || (err == nil && path == "") {
+			return exportMsg{}
+		}
+		if err != nil {
+			return exportMsg{prompt: true}
+		}
+		path, err = export.WriteFile(path, format, write)
+		return exportMsg{path: path, err: err}
+	}
+}
+
+// openExportPrompt opens the prompt asking for the path of the exported file.
+func (m *Model) openExportPrompt() {
+	m.ExportPrompt = true
+	m.ExportInput = "export" + m.ExportFormat.Extension()
+}
+
+// handleExportMsg reports the result of an export.
+//
+// Parameters:
+//   - msg: The result of the export.
+//
+// Returns:
+//   - tea.Model: The updated model.
+//   - tea.Cmd: A command to be executed, if any.
+func (m *Model) handleExportMsg(msg exportMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.prompt:
+		m.openExportPrompt()
+		return m, tea.ClearScreen
+	case msg.err != nil:
+		m.StatusMsg = fmt.Sprintf("Error exporting data: %v", msg.err)
+	case msg.path != "":
+		m.StatusMsg = fmt.Sprintf("Data successfully exported to %s", msg.path)
+	default:
+		m.StatusMsg = "Export canceled."
+	}
+	m.ExportHeaders, m.ExportRows, m.ExportSeries = nil, nil, nil
+	return m, tea.ClearScreen
+}
+
+// handleExportInput edits the path of the exported file, and writes the file on Enter.
+//
+// Parameters:
+//   - msg: A tea.KeyMsg representing the key message to be handled.
+//
+// Returns:
+//   - tea.Model: The updated model.
+//   - tea.Cmd: A command to be executed, if any.
+func (m *Model) handleExportInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.ExportPrompt = false
+		return m.handleExportMsg(exportMsg{})
+	case tea.KeyEnter:
+		path := strings.TrimSpace(m.ExportInput)
+		if path == "" {
+			return m, nil
+		}
+		m.ExportPrompt = false
+		path, err := export.WriteFile(path, m.ExportFormat, m.exportWriter(m.ExportFormat))
+		return m.handleExportMsg(exportMsg{path: path, err: err})
+	case tea.KeyBackspace:
+		if len(m.ExportInput) > 0 {
+			runes := []rune(m.ExportInput)
+			m.ExportInput = string(runes[:len(runes)-1])
+		}
+	case tea.KeySpace:
+		m.ExportInput += " "
+	case tea.KeyRunes:
+		m.ExportInput += string(msg.Runes)
+	}
+	return m, nil
+}
+
+// exportView renders the choice of the export format, or the prompt asking for the path of
+// the exported file.
+func (m Model) exportView() string {
+	if m.ExportChoosing {
+		var choices []string
+		for _, f := range exportFormats {
+			if !f.seriesOnly || m.ExportSeries != nil {
+				choices = append(choices, fmt.Sprintf("'%s' for %s", f.key, f.label))
+			}
+		}
+		return BaseStyle.Render(fmt.Sprintf("Export %d rows as %s.", len(m.ExportRows), strings.Join(choices, ", "))) +
+			"\nPress Esc to cancel."
+	}
+	return BaseStyle.Render(fmt.Sprintf("No file dialog is available. Save %d rows to: %s", len(m.ExportRows), m.ExportInput)) +
+		"\nPress Enter to save, or Esc to cancel."
+}
+
+// statusLine returns the status message of the current screen on its own line, if any.
+func (m Model) statusLine() string {
+	if m.StatusMsg == "" {
+		return ""
+	}
+	return "\n" + m.StatusMsg
+}
@@ -4,10 +4,12 @@
 package model
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 	"unicode"
 
@@ -16,8 +18,15 @@ import (
 	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/config"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/convert"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/draft"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/economy"
 	"github.com/simplesmentemat/stealth-grid-cli/pkg/export"
 	"github.com/simplesmentemat/stealth-grid-cli/pkg/graphql"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/library"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/summary"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/warehouse"
 	"github.com/sqweek/dialog"
 )
 
@@ -67,6 +76,37 @@ const (
 	// Downloading indicates that the application is in the state where data is being downloaded.
 	Downloading
 	SelectDownloadOption
+
+	// ConfirmRedownload indicates that the application is asking the user whether an already
+	// downloaded file should be downloaded again.
+	ConfirmRedownload
+
+	// WaitingForFiles indicates that the application is waiting for files of a series to become
+	// available before downloading them.
+	WaitingForFiles
+
+	// ShowSummary indicates that the application is displaying the post-match summary of a series.
+	ShowSummary
+
+	// ShowTimeline indicates that the application is displaying the event timeline of a series.
+	ShowTimeline
+
+	// ShowReplays indicates that the application is displaying the metadata of the replays of a series.
+	ShowReplays
+
+	// ShowDraft indicates that the application is displaying the picks and bans of a series.
+	ShowDraft
+
+	// ShowEconomy indicates that the application is displaying the economy timeline of a series.
+	ShowEconomy
+
+	// ChooseColumns indicates that the application is displaying the columns of the series
+	// table for the user to show, hide and reorder them.
+	ChooseColumns
+
+	// SelectProfile indicates that the application is displaying the profiles of the
+	// configuration file for the user to switch to another one.
+	SelectProfile
 )
 
 // Model represents the main application model.
@@ -84,6 +124,42 @@ type Model struct {
 	DownloadOption    string
 	DownloadOptions   []list.Item
 	DownloadListModel list.Model
+	TitleID           string
+	SelectedSeries    library.Series
+	Library           *library.Library
+	WaitStatus        string
+	cancelWait        context.CancelFunc
+	StatusMsg         string
+	Summary           *summary.Summary
+	SummaryTable      table.Model
+	SummaryPlayers    bool
+	Timeline          []TimelineEntry
+	TimelineTable     table.Model
+	TimelinePath      string
+	TimelineFilter    string
+	TimelineGame      int
+	TimelinePrompt    string
+	TimelineInput     string
+	ReplayInfo        []string
+	ReplayRows        [][]string
+	ReplayTable       table.Model
+	Draft             *draft.Draft
+	DraftTable        table.Model
+	Economy           *economy.Economy
+	EconomyTable      table.Model
+	EconomyRounds     bool
+	ExportChoosing    bool
+	ExportFormat      export.Format
+	ExportPrompt      bool
+	ExportInput       string
+	ExportHeaders     []string
+	ExportRows        [][]string
+	ExportSeries      []graphql.Series
+	Series            []graphql.Series
+	Columns           []export.Column
+	ColumnCursor      int
+	ProfileListModel  list.Model
+	ProfileReturn     State
 }
 
 // BaseStyle defines the base style for the application.
@@ -124,13 +200,34 @@ func InitModel(items []list.Item) Model {
 	dl := list.New(options, list.NewDefaultDelegate(), defaultWidth, listHeight)
 	dl.Title = "Select Download Option"
 
-	return Model{
+	pl := list.New(nil, list.NewDefaultDelegate(), defaultWidth, listHeight)
+	pl.Title = "Select a Profile"
+
+	// A library that cannot be loaded only disables the download markers, and downloads are
+	// then saved without being recorded.
+	lib, libErr := library.Open()
+
+	// Invalid configured columns fall back to the default columns.
+	columns, err := export.ParseColumns(config.GetSeriesColumns())
+	if err != nil {
+		columns = export.DefaultColumns()
+	}
+
+	m := Model{
 		ListModel:         l,
 		Spinner:           s,
 		CurrentState:      SelectGame,
 		DownloadOptions:   options,
 		DownloadListModel: dl,
+		Library:           lib,
+		Columns:           columns,
+		ProfileListModel:  pl,
 	}
+	if libErr != nil {
+		m.StatusMsg = fmt.Sprintf("Download library unavailable, downloads will not be recorded: %v", libErr)
+	}
+	m.selectDefaultTitle()
+	return m
 }
 
 // Init initializes the application.
@@ -167,41 +264,85 @@ func fetchDataCmd(titleID string, startTime, endTime time.Time) tea.Cmd {
 	}
 }
 
-// downloadDataCmd downloads data for the specified series ID to the specified directory.
+// downloadDirectory returns the directory a download is saved to: the download directory of
+// the active profile, created if needed, or else a directory chosen by the user in a dialog.
+//
+// Returns:
+//   - string: The directory, empty if the user cancelled the dialog.
+//   - error: An error if the directory cannot be created or chosen.
+func downloadDirectory() (string, error) {
+	if dir := config.GetDownloadDir(); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("error creating download directory: %v", err)
+		}
+		return dir, nil
+	}
+	return dialog.Directory().Title("Select Download Directory").Browse()
+}
+
+// downloadDataCmd downloads a file of the specified series to a directory selected by the user.
 //
-// This function creates a command that downloads a ZIP file containing data for a specified
-// series ID and saves it to the given directory. It returns a message indicating the download
-// status.
+// This function creates a command that gets a directory with downloadDirectory, downloads either the
+// events ZIP file or the replay of a game of the series into it, and records the downloaded
+// file in the local library. It returns a message indicating the download status.
 //
 // Parameters:
-//   - seriesID: A string representing the ID of the series to download the data for.
-//   - directory: A string representing the directory where the ZIP file will be saved.
+//   - lib: The library where the downloaded file is recorded.
+//   - series: The metadata of the series to download the file for.
+//   - option: The download option, either graphql.EventsFileID or a game number.
 //
 // Returns:
 //   - tea.Cmd: A command that downloads the data and returns a tea.Msg indicating the download status.
-func downloadDataCmd(seriesID string, option string) tea.Cmd {
+func downloadDataCmd(lib *library.Library, series library.Series, option string) tea.Cmd {
 	return func() tea.Msg {
-		directory, err := dialog.Directory().Title("Select Download Directory").Browse()
+		directory, err := downloadDirectory()
 		if err != nil || directory == "" {
 			return "Download cancelled or directory not selected"
 		}
 
-		if option == "events-grid-compressed" {
-			err := graphql.DownloadJSON(seriesID, directory)
+		var path string
+		if option == graphql.EventsFileID {
+			path, err = graphql.DownloadJSON(series.ID, directory)
 			if err != nil {
 				return fmt.Sprintf("Error downloading JSON: %v", err)
 			}
 		} else {
-			err := graphql.DownloadGame(seriesID, option, directory)
+			path, err = graphql.DownloadGame(series.ID, option, directory)
 			if err != nil {
 				return fmt.Sprintf("Error downloading ROFL for game %s: %v", option, err)
 			}
 		}
 
+		entry, err := recordDownload(lib, series, option, path)
+		if err != nil {
+			return fmt.Sprintf("Error recording download in library: %v", err)
+		}
+		if err := convert.PostDownload(entry); err != nil {
+			return err.Error()
+		}
+
 		return "Download complete"
 	}
 }
 
+// recordDownload records a downloaded file in the library, if it could be loaded.
+//
+// Parameters:
+//   - lib: The library where the file is recorded, or nil if it is not available.
+//   - series: The metadata of the series the file belongs to.
+//   - fileID: The identifier of the file within the series.
+//   - path: The path of the downloaded file.
+//
+// Returns:
+//   - library.Entry: The entry of the file, recorded or not.
+//   - error: An error if the file cannot be recorded in an available library.
+func recordDownload(lib *library.Library, series library.Series, fileID, path string) (library.Entry, error) {
+	if lib == nil {
+		return library.Entry{Series: series, FileID: fileID, Path: path}, nil
+	}
+	return lib.Record(series, fileID, path)
+}
+
 // Update handles messages and updates the application state.
 //
 // This function processes incoming messages and updates the application state accordingly.
@@ -223,16 +364,43 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case map[string]interface{}:
 		return m.handleDataMsg(msg)
 
+	case *summary.Summary:
+		return m.handleSummaryMsg(msg)
+
+	case timelineMsg:
+		return m.handleTimelineMsg(msg)
+
+	case replaysMsg:
+		return m.handleReplaysMsg(msg)
+
+	case *draft.Draft:
+		return m.handleDraftMsg(msg)
+
+	case *economy.Economy:
+		return m.handleEconomyMsg(msg)
+
+	case exportMsg:
+		return m.handleExportMsg(msg)
+
 	case string:
 		if msg == "Download complete" {
+			if m.cancelWait != nil {
+				m.cancelWait()
+				m.cancelWait = nil
+			}
 			m.CurrentState = ShowTable
 			m.Loading = false
+			m.markSavedRows()
 			return m, tea.Batch(tea.ClearScreen, m.Spinner.Tick)
 		} else if msg != "" {
 			m.ErrMsg = msg
 		}
 		return m, nil
 
+	case waitProgressMsg:
+		m.WaitStatus = msg.status
+		return m, listenWaitCmd(msg.ch)
+
 	case spinner.TickMsg:
 		if m.Loading {
 			var cmd tea.Cmd
@@ -267,20 +435,106 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 //   - tea.Model: The updated model.
 //   - tea.Cmd: A command to be executed, if any.
 func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.StatusMsg = ""
+	if m.ExportChoosing {
+		return m.handleExportFormatKey(msg)
+	}
+	if m.ExportPrompt {
+		return m.handleExportInput(msg)
+	}
+	if m.CurrentState == ShowTimeline {
+		return m.handleTimelineKey(msg)
+	}
+	if m.CurrentState == ChooseColumns {
+		return m.handleColumnsKey(msg)
+	}
+	if m.CurrentState == SelectProfile {
+		return m.handleProfileKey(msg)
+	}
 	switch msg.String() {
 	case "q", "ctrl+c":
 		return m, tea.Quit
 	case "enter":
 		return m.handleEnterKey()
 	case "e":
-		if m.CurrentState == ShowTable {
-			export.ExportData(m.Data)
+		var cmd tea.Cmd
+		if m.CurrentState == ShowTable && !m.Loading {
+			cmd = m.exportSeries()
+		} else if m.CurrentState == ShowSummary {
+			cmd = m.exportSummary()
+		} else if m.CurrentState == ShowReplays {
+			cmd = m.exportReplays()
+		} else if m.CurrentState == ShowDraft {
+			cmd = m.exportDraft()
+		} else if m.CurrentState == ShowEconomy {
+			cmd = m.exportEconomy()
 		}
-		return m, tea.ClearScreen
+		return m, tea.Batch(tea.ClearScreen, cmd)
+	case "s":
+		if m.CurrentState == ShowTable && !m.Loading {
+			return m.openSummary()
+		}
+		return m, nil
+	case "t":
+		if m.CurrentState == ShowTable && !m.Loading {
+			return m.openTimeline()
+		}
+		return m, nil
+	case "r":
+		if m.CurrentState == ShowTable && !m.Loading {
+			return m.openReplays()
+		}
+		if m.CurrentState == ShowEconomy && m.Economy != nil {
+			m.EconomyRounds = !m.EconomyRounds
+			m.setEconomyTable()
+		}
+		return m, nil
+	case "d":
+		if m.CurrentState == ShowTable && !m.Loading {
+			return m.openDraft()
+		}
+		return m, nil
+	case "m":
+		if m.CurrentState == ShowTable && !m.Loading {
+			return m.openEconomy()
+		}
+		return m, nil
+	case "c":
+		if m.CurrentState == ShowTable && !m.Loading {
+			return m.openColumns()
+		}
+		return m, nil
+	case "P":
+		if m.CurrentState == SelectGame || (m.CurrentState == ShowTable && !m.Loading) {
+			return m.openProfiles()
+		}
+		return m, nil
+	case "p":
+		if m.CurrentState == ShowSummary && m.Summary != nil {
+			m.SummaryPlayers = !m.SummaryPlayers
+			m.setSummaryTable()
+		}
+		return m, nil
 	case "backspace":
 		return m.handleBackspaceKey()
+	case "esc":
+		if m.CurrentState == WaitingForFiles {
+			m.cancelWait()
+			m.Loading = false
+			m.CurrentState = SelectDownloadOption
+			return m, tea.ClearScreen
+		}
+		if m.CurrentState == ShowSummary || m.CurrentState == ShowReplays || m.CurrentState == ShowDraft || m.CurrentState == ShowEconomy {
+			return m.handleBackspaceKey()
+		}
+		return m, nil
+	case "y", "n":
+		if m.CurrentState == ConfirmRedownload {
+			return m.handleConfirmRedownload(msg.String() == "y")
+		}
+		return m, nil
 	case "up", "down":
-		if m.CurrentState == SelectGame || m.CurrentState == ShowTable || m.CurrentState == SelectDownloadOption {
+		if m.CurrentState == SelectGame || m.CurrentState == ShowTable || m.CurrentState == SelectDownloadOption || m.CurrentState == ShowSummary || m.CurrentState == ShowReplays || m.CurrentState == ShowDraft || m.CurrentState == ShowEconomy {
 			var cmd tea.Cmd
 			if m.CurrentState == SelectGame {
 				m.ListModel, cmd = m.ListModel.Update(msg)
@@ -288,6 +542,14 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.Table, cmd = m.Table.Update(msg)
 			} else if m.CurrentState == SelectDownloadOption {
 				m.DownloadListModel, cmd = m.DownloadListModel.Update(msg)
+			} else if m.CurrentState == ShowSummary {
+				m.SummaryTable, cmd = m.SummaryTable.Update(msg)
+			} else if m.CurrentState == ShowReplays {
+				m.ReplayTable, cmd = m.ReplayTable.Update(msg)
+			} else if m.CurrentState == ShowDraft {
+				m.DraftTable, cmd = m.DraftTable.Update(msg)
+			} else if m.CurrentState == ShowEconomy {
+				m.EconomyTable, cmd = m.EconomyTable.Update(msg)
 			}
 			return m, cmd
 		}
@@ -310,6 +572,7 @@ func (m *Model) handleEnterKey() (tea.Model, tea.Cmd) {
 	case SelectGame:
 		selectedItem := m.ListModel.SelectedItem().(Item)
 		m.SelectedID = selectedItem.ID
+		m.TitleID = selectedItem.ID
 		m.CurrentState = EnterStartDays
 		return m, nil
 	case EnterStartDays:
@@ -324,9 +587,19 @@ func (m *Model) handleEnterKey() (tea.Model, tea.Cmd) {
 		m.CurrentState = ShowTable
 		return m, tea.Batch(tea.ClearScreen, fetchDataCmd(m.SelectedID, startTime, endTime), m.Spinner.Tick)
 	case ShowTable:
-		selectedRow := m.Table.SelectedRow()
+		selected, ok := m.selectedSeries()
+		if !ok {
+			return m, nil
+		}
 		m.CurrentState = SelectDownloadOption
-		m.SelectedID = selectedRow[1]
+		m.SelectedID = selected.ID
+		m.SelectedSeries = library.Series{
+			ID:         selected.ID,
+			TitleID:    m.TitleID,
+			StartTime:  selected.StartTimeScheduled,
+			Tournament: selected.Tournament.Name,
+			Teams:      selected.TeamNames(),
+		}
 
 		roflCount, hasJSON, err := graphql.FetchGameList(m.SelectedID)
 		if err != nil {
@@ -347,10 +620,30 @@ func (m *Model) handleEnterKey() (tea.Model, tea.Cmd) {
 
 		var options []list.Item
 		if hasJSON {
-			options = append(options, Item{TitleText: "Download JSON", ID: "events-grid-compressed"})
+			options = append(options, m.downloadOption("Download JSON", graphql.EventsFileID))
 		}
 		for i := 1; i <= roflCount; i++ {
-			options = append(options, Item{TitleText: fmt.Sprintf("Download Game %d", i), ID: strconv.Itoa(i)})
+			options = append(options, m.downloadOption(fmt.Sprintf("Download Game %d", i), strconv.Itoa(i)))
+		}
+		if !hasJSON {
+			options = append(options, Item{
+				TitleText:       "Download JSON when ready",
+				DescriptionText: "Wait until the events file is available, then download it",
+				ID:              waitOptionPrefix + graphql.EventsFileID,
+			})
+		}
+		// The replays of the games not played yet can be waited for, up to the format of the
+		// series, or the next game if the format is unknown.
+		lastGame := selected.Format.BestOf()
+		if lastGame == 0 {
+			lastGame = roflCount + 1
+		}
+		for i := roflCount + 1; i <= lastGame; i++ {
+			options = append(options, Item{
+				TitleText:       fmt.Sprintf("Download Game %d when ready", i),
+				DescriptionText: fmt.Sprintf("Wait until the replay of game %d is available, then download it", i),
+				ID:              waitOptionPrefix + strconv.Itoa(i),
+			})
 		}
 		m.DownloadOptions = options
 		m.DownloadListModel.SetItems(options)
@@ -359,31 +652,118 @@ func (m *Model) handleEnterKey() (tea.Model, tea.Cmd) {
 	case SelectDownloadOption:
 		selectedOption := m.DownloadListModel.SelectedItem().(Item)
 		m.DownloadOption = selectedOption.ID
+		if fileID, ok := strings.CutPrefix(m.DownloadOption, waitOptionPrefix); ok {
+			ctx, cancel := context.WithTimeout(context.Background(), waitTimeout)
+			m.cancelWait = cancel
+			m.WaitStatus = ""
+			m.CurrentState = WaitingForFiles
+			m.Loading = true
+			return m, tea.Batch(tea.ClearScreen, waitForFilesCmd(ctx, m.Library, m.SelectedSeries, fileID), m.Spinner.Tick)
+		}
+		if _, ok := m.Library.Find(m.SelectedID, m.DownloadOption); ok {
+			m.CurrentState = ConfirmRedownload
+			return m, tea.ClearScreen
+		}
 		m.CurrentState = Downloading
 		m.Loading = true
-		return m, tea.Batch(tea.ClearScreen, downloadDataCmd(m.SelectedID, m.DownloadOption), m.Spinner.Tick)
+		return m, tea.Batch(tea.ClearScreen, downloadDataCmd(m.Library, m.SelectedSeries, m.DownloadOption), m.Spinner.Tick)
 	case Downloading:
 		m.Loading = false
 		m.CurrentState = ShowTable
 		return m, tea.ClearScreen
 	case SelectSeries:
 		m.Loading = true
-		directory, err := dialog.Directory().Title("Select Download Directory").Browse()
+		directory, err := downloadDirectory()
 		if err != nil || directory == "" {
 			m.Loading = false
 			m.CurrentState = ShowTable
 			return m, tea.ClearScreen
 		}
 
-		return m, tea.Batch(tea.ClearScreen, downloadDataCmd(m.SelectedID, m.DownloadOption), m.Spinner.Tick)
+		return m, tea.Batch(tea.ClearScreen, downloadDataCmd(m.Library, m.SelectedSeries, m.DownloadOption), m.Spinner.Tick)
 	}
 	return m, nil
 }
 
+// handleConfirmRedownload handles the answer to the re-download prompt.
+//
+// Parameters:
+//   - confirmed: Whether the user chose to download the file again.
+//
+// Returns:
+//   - tea.Model: The updated model.
+//   - tea.Cmd: A command to be executed, if any.
+func (m *Model) handleConfirmRedownload(confirmed bool) (tea.Model, tea.Cmd) {
+	if !confirmed {
+		m.CurrentState = SelectDownloadOption
+		return m, tea.ClearScreen
+	}
+	m.CurrentState = Downloading
+	m.Loading = true
+	return m, tea.Batch(tea.ClearScreen, downloadDataCmd(m.Library, m.SelectedSeries, m.DownloadOption), m.Spinner.Tick)
+}
+
+// downloadOption builds a download menu item, noting where the file was saved if it is
+// already in the library.
+//
+// Parameters:
+//   - title: The title of the menu item.
+//   - fileID: The identifier of the file within the selected series.
+//
+// Returns:
+//   - Item: The download menu item.
+func (m *Model) downloadOption(title, fileID string) Item {
+	item := Item{TitleText: title, ID: fileID}
+	if entry, ok := m.Library.Find(m.SelectedID, fileID); ok {
+		item.TitleText += " ✓"
+		item.DescriptionText = "Saved to " + entry.Path
+	}
+	return item
+}
+
+// savedMarker returns the marker displayed in the series table for a series that has
+// downloaded files in the library.
+func (m *Model) savedMarker(seriesID string) string {
+	if m.Library.Has(seriesID) {
+		return "✓"
+	}
+	return ""
+}
+
+// markSavedRows refreshes the saved marker of every row in the series table.
+func (m *Model) markSavedRows() {
+	for i, row := range m.Data {
+		row[len(row)-1] = m.savedMarker(m.Series[i].ID)
+		m.Data[i] = row
+	}
+	m.Table.SetRows(m.Data)
+}
+
+// selectedSeries returns the series selected in the table.
+//
+// Returns:
+//   - graphql.Series: The selected series.
+//   - bool: False if the table is empty.
+func (m *Model) selectedSeries() (graphql.Series, bool) {
+	i := m.Table.Cursor()
+	if i < 0 || i >= len(m.Series) {
+		return graphql.Series{}, false
+	}
+	return m.Series[i], true
+}
+
+// selectedSeriesID returns the ID of the series selected in the table, or an empty string if
+// the table is empty.
+func (m *Model) selectedSeriesID() string {
+	s, _ := m.selectedSeries()
+	return s.ID
+}
+
 // handleBackspaceKey handles the 'backspace' key press.
 //
 // This function processes the 'backspace' key press to delete the last character
-// in the StartDays or EndDays fields based on the current state of the application.
+// in the StartDays or EndDays fields based on the current state of the application,
+// or to go back to the series table from the summary, replay, draft and economy screens.
 //
 // Returns:
 //   - tea.Model: The updated model.
@@ -393,6 +773,26 @@ func (m *Model) handleBackspaceKey() (tea.Model, tea.Cmd) {
 		m.StartDays = m.StartDays[:len(m.StartDays)-1]
 	} else if m.CurrentState == EnterEndDays && len(m.EndDays) > 0 {
 		m.EndDays = m.EndDays[:len(m.EndDays)-1]
+	} else if m.CurrentState == ShowSummary {
+		m.CurrentState = ShowTable
+		m.Loading = false
+		m.Summary = nil
+		return m, tea.ClearScreen
+	} else if m.CurrentState == ShowReplays {
+		m.CurrentState = ShowTable
+		m.Loading = false
+		m.ReplayRows = nil
+		return m, tea.ClearScreen
+	} else if m.CurrentState == ShowDraft {
+		m.CurrentState = ShowTable
+		m.Loading = false
+		m.Draft = nil
+		return m, tea.ClearScreen
+	} else if m.CurrentState == ShowEconomy {
+		m.CurrentState = ShowTable
+		m.Loading = false
+		m.Economy = nil
+		return m, tea.ClearScreen
 	}
 	return m, nil
 }
@@ -424,8 +824,7 @@ func (m *Model) handleDefaultKey(key string) (tea.Model, tea.Cmd) {
 // handleDataMsg handles data messages.
 //
 // This function processes incoming data messages, updates the application state with the
-// retrieved data, and constructs a table to display the series information. It performs
-// several checks to ensure the data is valid and sets error messages if any issues are found.
+// retrieved series, and constructs a table to display them in the configured columns.
 //
 // Parameters:
 //   - msg: A map[string]interface{} representing the data message to be handled.
@@ -436,85 +835,84 @@ func (m *Model) handleDefaultKey(key string) (tea.Model, tea.Cmd) {
 //
 // Processing Steps:
 //  1. Clear any existing error message and set loading to false.
-//  2. Extract the data from the message. If the data is not found, set an error message.
-//  3. Extract the series data from the data map. If the series data is not found, set an error message.
-//  4. Extract the edges array from the series map. If the edges array is not found, set an error message.
-//  5. Iterate over the edges array to extract relevant data for each series, including the start time, series ID,
-//     tournament name, and team names. Ensure there are at least two teams.
-//  6. Construct table rows from the extracted data and append them to the rows slice.
-//  7. Sort the rows by start time in ascending order.
-//  8. Define the table columns.
-//  9. Create a new table with the specified columns, rows, and styles.
-// 10. Define the table styles for the headers and selected rows.
-// 11. Update the model with the new table and data.
-// 12. Return the updated model and no additional command.
-//
-// Error Handling:
-//   - The function includes checks to ensure the data is valid at each step, setting error messages if any issues are found.
+//  2. Decode the series of the response. If the response holds no series, set an error message.
+//  3. Keep the series with at least two teams and sort them by start time in ascending order.
+//  4. Build the series table from the series, in the columns of m.Columns.
 func (m *Model) handleDataMsg(msg map[string]interface{}) (tea.Model, tea.Cmd) {
 	m.ErrMsg = ""
 	m.Loading = false
-	data, ok := msg["data"].(map[string]interface{})
-	if !ok {
-		m.ErrMsg = "No data found"
-		return m, nil
-	}
-
-	series, ok := data["allSeries"].(map[string]interface{})
-	if !ok {
+	parsed, err := graphql.ParseSeries(msg)
+	if err != nil {
 		m.ErrMsg = "No series found"
 		return m, nil
 	}
 
-	edges, ok := series["edges"].([]interface{})
-	if !ok {
-		m.ErrMsg = "No edges found"
-		return m, nil
-	}
-
-	var rows []table.Row
-	for _, edge := range edges {
-		node := edge.(map[string]interface{})["node"].(map[string]interface{})
-		tournament := node["tournament"].(map[string]interface{})
-		teams := node["teams"].([]interface{})
-
-		if len(teams) < 2 {
-			continue
+	var series []graphql.Series
+	for _, s := range parsed {
+		if len(s.Teams) >= 2 {
+			series = append(series, s)
 		}
+	}
+	sort.SliceStable(series, func(i, j int) bool {
+		timeI, _ := time.Parse(time.RFC3339, series[i].StartTimeScheduled)
+		timeJ, _ := time.Parse(time.RFC3339, series[j].StartTimeScheduled)
+		return timeI.Before(timeJ)
+	})
 
-		team1 := teams[0].(map[string]interface{})["baseInfo"].(map[string]interface{})["name"].(string)
-		team2 := teams[1].(map[string]interface{})["baseInfo"].(map[string]interface{})["name"].(string)
+	// A new search starts at the first series.
+	m.Series = series
+	m.Table = table.Model{}
+	m.setSeriesTable()
+	return m, nil
+}
 
-		row := table.Row{
-			node["startTimeScheduled"].(string),
-			node["id"].(string),
-			tournament["name"].(string),
-			team1,
-			team2,
+// setSeriesTable fills the series table with the series in the columns of m.Columns, followed
+// by the saved marker, keeping the selected row. The results of the series are loaded from the
+// warehouse when a column reads them.
+func (m *Model) setSeriesTable() {
+	if export.NeedsResults(m.Columns) {
+		if err := warehouse.LoadResults(m.Series); err != nil {
+			m.StatusMsg = fmt.Sprintf("Error loading series results: %v", err)
 		}
-		rows = append(rows, row)
+	}
+	headers := append(export.Headers(m.Columns), "Saved")
+	rows := make([][]string, len(m.Series))
+	for i, s := range m.Series {
+		rows[i] = append(export.ColumnRow(s, m.Columns), m.savedMarker(s.ID))
 	}
 
-	sort.SliceStable(rows, func(i, j int) bool {
-		timeI, _ := time.Parse(time.RFC3339, rows[i][0])
-		timeJ, _ := time.Parse(time.RFC3339, rows[j][0])
-		return timeI.Before(timeJ)
-	})
+	cursor := m.Table.Cursor()
+	m.Table = newStyledTable(columnsFor(headers, rows), toTableRows(rows), 15)
+	if cursor >= 0 && cursor < len(rows) {
+		m.Table.SetCursor(cursor)
+	}
+	m.Data = m.Table.Rows()
+}
 
-	columns := []table.Column{
-		{Title: "Start Time", Width: 20},
-		{Title: "Serie ID", Width: 10},
-		{Title: "Tournament", Width: 20},
-		 {Title: "Team One", Width: 20},
-		{Title: "Team Two", Width: 20},
+// newStyledTable creates a focused table with the application's styles.
+//
+// Parameters:
+//   - columns: The columns of the table.
+//   - rows: The rows of the table.
+//   - height: The number of rows displayed at once.
+//
+// Returns:
+//   - table.Model: The styled table.
+func newStyledTable(columns []table.Column, rows []table.Row, height int) table.Model {
+	width := 0
+	for _, c := range columns {
+		width += c.Width + 2
+	}
+	if width < 100 {
+		width = 100
 	}
 
 	t := table.New(
 		table.WithColumns(columns),
 		table.WithRows(rows),
 		table.WithFocused(true),
-		table.WithHeight(15),
-		table.WithWidth(100),
+		table.WithHeight(height),
+		table.WithWidth(width),
 	)
 
 	s := table.DefaultStyles()
@@ -528,10 +926,7 @@ func (m *Model) handleDataMsg(msg map[string]interface{}) (tea.Model, tea.Cmd) {
 		Background(lipgloss.Color("57")).
 		Bold(false)
 	t.SetStyles(s)
-
-	m.Table = t
-	m.Data = rows
-	return m, nil
+	return t
 }
 
 // View returns the current view of the application.
@@ -546,9 +941,15 @@ func (m Model) View() string {
 	if m.ErrMsg != "" {
 		return m.ErrMsg
 	}
+	if m.ExportChoosing || m.ExportPrompt {
+		return m.exportView()
+	}
 	switch m.CurrentState {
 	case SelectGame:
-		return BaseStyle.Render(m.ListModel.View())
+		return BaseStyle.Render(m.ListModel.View()) + m.profileLine() + m.statusLine()
+	case SelectProfile:
+		return BaseStyle.Render(m.ProfileListModel.View()) +
+			"\nPress Enter to switch to a profile, or Esc to go back." + m.statusLine()
 	case EnterStartDays:
 		return BaseStyle.Render("Enter the number of past days to include (e.g., 10): " + m.StartDays)
 	case EnterEndDays:
@@ -557,9 +958,32 @@ func (m Model) View() string {
 		if m.Loading {
 			return BaseStyle.Render(fmt.Sprintf("\n\n   %s Loading data, please wait...  \n\n", m.Spinner.View()))
 		}
-		return BaseStyle.Render(m.Table.View()) + "\nPress 'e' to export data, or press Enter to select a series."
+		view := BaseStyle.Render(m.Table.View()) + "\nPress 'e' to export data, 'c' to choose the columns, 's' to summarize, 't' to browse the events or 'd' to show the draft of a downloaded series, 'm' its economy, 'r' to read its replays, 'P' to switch profiles, or press Enter to select a series."
+		if m.StatusMsg != "" {
+			view += "\n" + m.StatusMsg
+		}
+		return view
+	case ShowSummary:
+		return m.summaryView()
+	case ShowTimeline:
+		return m.timelineView()
+	case ChooseColumns:
+		return m.columnsView()
+	case ShowReplays:
+		return m.replaysView()
+	case ShowDraft:
+		return m.draftView()
+	case ShowEconomy:
+		return m.economyView()
 	case SelectDownloadOption:
 		return BaseStyle.Render(m.DownloadListModel.View())
+	case ConfirmRedownload:
+		entry, _ := m.Library.Find(m.SelectedID, m.DownloadOption)
+		return BaseStyle.Render(fmt.Sprintf("This file was already downloaded to %s on %s.\nPress 'y' to download it again or 'n' to go back.",
+			entry.Path, entry.DownloadedAt.Local().Format("2006-01-02 15:04")))
+	case WaitingForFiles:
+		return BaseStyle.Render(fmt.Sprintf("\n\n   %s Waiting for the files of series %s to become available...  \n   %s\n\n   Press Esc to stop waiting.\n\n",
+			m.Spinner.View(), m.SelectedID, m.WaitStatus))
 	case Downloading:
 		if m.Loading {
 			return BaseStyle.Render(fmt.Sprintf("\n\n   %s Downloading data, please wait...  \n\n", m.Spinner.View()))
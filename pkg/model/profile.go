@@ -0,0 +1,135 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/config"
+)
+
+// openProfiles displays the profiles of the configuration file for the user to switch to another one.
+//
+// If no profile is configured, a status message is displayed instead.
+//
+// Returns:
+//   - tea.Model: The updated model.
+//   - tea.Cmd: A command to be executed, if any.
+func (m *Model) openProfiles() (tea.Model, tea.Cmd) {
+	profiles := config.Profiles()
+	if len(profiles) == 0 {
+		m.StatusMsg = "No profiles are configured. Add them under 'profiles' in the configuration file."
+		return m, nil
+	}
+
+	active := config.ActiveProfile()
+	items := []list.Item{profileItem("default", "The top-level settings of the configuration file", "", active == "")}
+	for _, p := range profiles {
+		var details []string
+		if p.APIURL != "" {
+			details = append(details, "API "+p.APIURL)
+		}
+		if p.DefaultTitle != "" {
+			details = append(details, "title "+p.DefaultTitle)
+		}
+		if p.DownloadDir != "" {
+			details = append(details, "downloads to "+p.DownloadDir)
+		}
+		items = append(items, profileItem(p.Name, strings.Join(details, ", "), p.Name, active == p.Name))
+	}
+	m.ProfileListModel.SetItems(items)
+	for i, item := range items {
+		if item.(Item).ID == active {
+			m.ProfileListModel.Select(i)
+		}
+	}
+	m.ProfileReturn = m.CurrentState
+	m.CurrentState = SelectProfile
+	return m, tea.ClearScreen
+}
+
+// profileItem builds an item of the profile list, marking the active profile.
+func profileItem(title, description, name string, active bool) Item {
+	if active {
+		title += " ✓"
+	}
+	return Item{TitleText: title, DescriptionText: description, ID: name}
+}
+
+// handleProfileKey handles the keys of the profile list.
+//
+// Parameters:
+//   - msg: The key message to be handled.
+//
+// Returns:
+//   - tea.Model: The updated model.
+//   - tea.Cmd: A command to be executed, if any.
+func (m *Model) handleProfileKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "enter":
+		return m.switchProfile(m.ProfileListModel.SelectedItem().(Item).ID)
+	case "esc", "backspace":
+		m.CurrentState = m.ProfileReturn
+		return m, tea.ClearScreen
+	}
+	var cmd tea.Cmd
+	m.ProfileListModel, cmd = m.ProfileListModel.Update(msg)
+	return m, cmd
+}
+
+// switchProfile activates a profile and starts over from the game selection, since the series
+// listed so far were fetched with the API key and URL of the previous profile.
+//
+// Parameters:
+//   - name: The name of the profile, or an empty string for the top-level settings.
+//
+// Returns:
+//   - tea.Model: The updated model.
+//   - tea.Cmd: A command to be executed, if any.
+func (m *Model) switchProfile(name string) (tea.Model, tea.Cmd) {
+	if err := config.UseProfile(name); err != nil {
+		m.StatusMsg = err.Error()
+		return m, nil
+	}
+	m.Series = nil
+	m.Data = nil
+	m.Table = table.Model{}
+	m.StartDays = ""
+	m.EndDays = ""
+	m.CurrentState = SelectGame
+	m.selectDefaultTitle()
+	if name == "" {
+		name = "default"
+	}
+	m.StatusMsg = fmt.Sprintf("Switched to the %s profile.", name)
+	return m, tea.ClearScreen
+}
+
+// selectDefaultTitle selects the default title of the active profile in the game list, if any.
+func (m *Model) selectDefaultTitle() {
+	title := config.GetDefaultTitle()
+	if title == "" {
+		return
+	}
+	for i, item := range m.ListModel.Items() {
+		if it, ok := item.(Item); ok && it.ID == title {
+			m.ListModel.Select(i)
+		}
+	}
+}
+
+// profileLine returns the active profile on its own line, if profiles are configured.
+func (m Model) profileLine() string {
+	if len(config.Profiles()) == 0 {
+		return ""
+	}
+	name := config.ActiveProfile()
+	if name == "" {
+		name = "default"
+	}
+	return fmt.Sprintf("\nProfile: %s. Press 'P' to switch profiles.", name)
+}
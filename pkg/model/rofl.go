@@ -0,0 +1,110 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/graphql"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/library"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/rofl"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/summary"
+)
+
+// replayHeaders are the headers of the replay metadata table.
+var replayHeaders = append([]string{"Game"}, rofl.PlayerHeaders...)
+
+// replaysMsg delivers the metadata of the downloaded replays of a series.
+type replaysMsg struct {
+	info []string   // info describes each replay on one line.
+	rows [][]string // rows are the players of every replay, matching replayHeaders.
+}
+
+// replaysCmd reads the metadata of replays and writes it to a JSON file next to each replay.
+//
+// Parameters:
+//   - entries: The library entries of the replays, with the game number as file ID.
+//
+// Returns:
+//   - tea.Cmd: A command that returns a replaysMsg, or an error message.
+func replaysCmd(entries []library.Entry) tea.Cmd {
+	return func() tea.Msg {
+		var msg replaysMsg
+		for _, e := range entries {
+			m, err := rofl.ParseFile(e.Path)
+			if err != nil {
+				return fmt.Sprintf("Error reading replay of game %s: %v", e.FileID, err)
+			}
+			if _, err := rofl.WriteSidecar(e.Path, m); err != nil {
+				return err.Error()
+			}
+			msg.info = append(msg.info, fmt.Sprintf("Game %s: %s, version %s (patch %s)",
+				e.FileID, summary.FormatDuration(m.Duration()), m.GameVersion, m.Patch))
+			for _, row := range m.PlayerRows() {
+				msg.rows = append(msg.rows, append([]string{e.FileID}, row...))
+			}
+		}
+		return msg
+	}
+}
+
+// openReplays starts reading the downloaded replays of the series selected in the table.
+//
+// If no replay of the series is in the download library, a status message is displayed
+// below the table.
+//
+// Returns:
+//   - tea.Model: The updated model.
+//   - tea.Cmd: A command to be executed, if any.
+func (m *Model) openReplays() (tea.Model, tea.Cmd) {
+	id := m.selectedSeriesID()
+	if id == "" {
+		return m, nil
+	}
+	var entries []library.Entry
+	for _, e := range m.Library.Series(id) {
+		if e.FileID != graphql.EventsFileID {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) == 0 {
+		m.StatusMsg = fmt.Sprintf("No replay of series %s is downloaded yet. Press Enter to download one.", id)
+		return m, nil
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].FileID < entries[j].FileID })
+
+	m.CurrentState = ShowReplays
+	m.SelectedID = id
+	m.Loading = true
+	return m, tea.Batch(tea.ClearScreen, replaysCmd(entries), m.Spinner.Tick)
+}
+
+// handleReplaysMsg displays the metadata of loaded replays.
+func (m *Model) handleReplaysMsg(msg replaysMsg) (tea.Model, tea.Cmd) {
+	m.Loading = false
+	m.ReplayInfo = msg.info
+	m.ReplayRows = msg.rows
+	m.ReplayTable = newStyledTable(columnsFor(replayHeaders, msg.rows), toTableRows(msg.rows), 15)
+	return m, nil
+}
+
+// exportReplays exports the replay metadata table.
+func (m *Model) exportReplays() tea.Cmd {
+	if m.ReplayRows == nil {
+		return nil
+	}
+	return m.exportTable(replayHeaders, m.ReplayRows)
+}
+
+// replaysView renders the replay metadata screen.
+func (m Model) replaysView() string {
+	if m.Loading {
+		return BaseStyle.Render(fmt.Sprintf("\n\n   %s Reading replays, please wait...  \n\n", m.Spinner.View()))
+	}
+	view := fmt.Sprintf("Series %s\n", m.SelectedID)
+	for _, line := range m.ReplayInfo {
+		view += line + "\n"
+	}
+	return view + BaseStyle.Render(m.ReplayTable.View()) +
+		"\nThe metadata was written next to each replay. Press 'e' to export, or Esc to go back." + m.statusLine()
+}
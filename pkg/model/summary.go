@@ -0,0 +1,134 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/graphql"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/summary"
+)
+
+// summarizeCmd builds the summary of a series from its events archive.
+//
+// Parameters:
+//   - path: The path of the events archive of the series.
+//
+// Returns:
+//   - tea.Cmd: A command that returns the *summary.Summary, or an error message.
+func summarizeCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		s, err := summary.SummarizeArchive(path)
+		if err != nil {
+			return fmt.Sprintf("Error summarizing series: %v", err)
+		}
+		return s
+	}
+}
+
+// openSummary starts loading the summary of the series selected in the table.
+//
+// The events archive of the series must be in the download library, otherwise a status
+// message is displayed below the table.
+//
+// Returns:
+//   - tea.Model: The updated model.
+//   - tea.Cmd: A command to be executed, if any.
+func (m *Model) openSummary() (tea.Model, tea.Cmd) {
+	path, ok := m.selectedArchive()
+	if !ok {
+		return m, nil
+	}
+	m.CurrentState = ShowSummary
+	m.Loading = true
+	m.SummaryPlayers = false
+	return m, tea.Batch(tea.ClearScreen, summarizeCmd(path), m.Spinner.Tick)
+}
+
+// selectedArchive returns the path of the events archive of the series selected in the table.
+//
+// If the archive is not in the download library, the status message is set and false is returned.
+func (m *Model) selectedArchive() (string, bool) {
+	id := m.selectedSeriesID()
+	if id == "" {
+		return "", false
+	}
+	entry, ok := m.Library.Find(id, graphql.EventsFileID)
+	if !ok {
+		m.StatusMsg = fmt.Sprintf("The events of series %s are not downloaded yet. Press Enter to download them.", id)
+		return "", false
+	}
+	return entry.Path, true
+}
+
+// handleSummaryMsg displays a loaded series summary.
+func (m *Model) handleSummaryMsg(s *summary.Summary) (tea.Model, tea.Cmd) {
+	m.Loading = false
+	m.Summary = s
+	m.setSummaryTable()
+	return m, nil
+}
+
+// setSummaryTable fills the summary table with the per game or the per player rows.
+func (m *Model) setSummaryTable() {
+	headers, rows := m.summaryRows()
+	m.SummaryTable = newStyledTable(columnsFor(headers, rows), toTableRows(rows), 15)
+}
+
+// summaryRows returns the headers and rows of the summary table currently displayed.
+func (m *Model) summaryRows() ([]string, [][]string) {
+	if m.SummaryPlayers {
+		return summary.PlayerHeaders, m.Summary.PlayerRows()
+	}
+	return summary.TeamHeaders, m.Summary.TeamRows()
+}
+
+// exportSummary exports the summary table currently displayed.
+func (m *Model) exportSummary() tea.Cmd {
+	if m.Summary == nil {
+		return nil
+	}
+	return m.exportTable(m.summaryRows())
+}
+
+// summaryView renders the summary screen.
+func (m Model) summaryView() string {
+	if m.Loading || m.Summary == nil {
+		return BaseStyle.Render(fmt.Sprintf("\n\n   %s Reading events, please wait...  \n\n", m.Spinner.View()))
+	}
+	view := "players"
+	if m.SummaryPlayers {
+		view = "games"
+	}
+	return fmt.Sprintf("Series %s: %s\n", m.Summary.SeriesID, m.Summary.Score()) +
+		BaseStyle.Render(m.SummaryTable.View()) +
+		fmt.Sprintf("\nPress 'p' to show %s, 'e' to export, or Esc to go back.", view) + m.statusLine()
+}
+
+// columnsFor sizes table columns to fit their headers and content.
+func columnsFor(headers []string, rows [][]string) []table.Column {
+	const maxWidth = 40
+	columns := make([]table.Column, len(headers))
+	for i, h := range headers {
+		width := len(h)
+		for _, row := range rows {
+			if i < len(row) && len(row[i]) > width {
+				width = len(row[i])
+			}
+		}
+		if width > maxWidth {
+			width = maxWidth
+		}
+		columns[i] = table.Column{Title: h, Width: width}
+	}
+	return columns
+}
+
+// toTableRows converts plain rows to table rows.
+func toTableRows(rows [][]string) []table.Row {
+	tableRows := make([]table.Row, len(rows))
+	for i, row := range rows {
+		tableRows[i] = row
+	}
+	return tableRows
+}
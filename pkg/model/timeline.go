@@ -0,0 +1,329 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/filter"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/summary"
+)
+
+const (
+	// timelinePromptFilter is the prompt of the filter expression input of the timeline.
+	timelinePromptFilter = "Filter"
+
+	// timelinePromptJump is the prompt of the jump-to-time input of the timeline.
+	timelinePromptJump = "Jump to (mm:ss)"
+)
+
+// timelineHeaders are the headers of the timeline table.
+var timelineHeaders = []string{"Game", "Game Time", "Occurred At", "Type", "Actor", "Target"}
+
+// TimelineEntry is an event of the timeline, reduced to what is displayed.
+type TimelineEntry struct {
+	GameNumber int           // GameNumber is the number of the game in progress, 0 between games.
+	GameTime   time.Duration // GameTime is the time elapsed since the start of the game in progress.
+	OccurredAt time.Time     // OccurredAt is the time the event occurred.
+	Type       string        // Type is the type of the event.
+	Actor      string        // Actor describes the actor of the event.
+	Target     string        // Target describes the target of the event.
+}
+
+// timelineMsg delivers the events of a timeline loaded with the given filter expression.
+type timelineMsg struct {
+	expr    string          // expr is the filter expression the events were selected with.
+	entries []TimelineEntry // entries are the selected events, in order.
+}
+
+// timelineCmd reads the events of an archive matching a filter expression.
+//
+// Only the displayed fields of the events are kept, so that the whole archive is read again
+// each time the filter changes rather than being held in memory.
+//
+// Parameters:
+//   - path: The path of the events archive of the series.
+//   - expr: The filter expression, empty for every event.
+//
+// Returns:
+//   - tea.Cmd: A command that returns a timelineMsg, or an error message.
+func timelineCmd(path, expr string) tea.Cmd {
+	return func() tea.Msg {
+		f, err := filter.Parse(expr)
+		if err != nil {
+			return fmt.Sprintf("Error parsing filter: %v", err)
+		}
+		archive, err := events.Open(path)
+		if err != nil {
+			return fmt.Sprintf("Error reading events: %v", err)
+		}
+		defer archive.Close()
+
+		var entries []TimelineEntry
+		for m, err := range f.Apply(archive.Events()) {
+			if err != nil {
+				return fmt.Sprintf("Error reading events: %v", err)
+			}
+			entries = append(entries, TimelineEntry{
+				GameNumber: m.GameNumber,
+				GameTime:   m.GameTime,
+				OccurredAt: m.OccurredAt,
+				Type:       m.Type,
+				Actor:      m.Actor.Label(),
+				Target:     m.Target.Label(),
+			})
+		}
+		return timelineMsg{expr: expr, entries: entries}
+	}
+}
+
+// openTimeline starts loading the timeline of the series selected in the table.
+//
+// The events archive of the series must be in the download library, otherwise a status
+// message is displayed below the table.
+//
+// Returns:
+//   - tea.Model: The updated model.
+//   - tea.Cmd: A command to be executed, if any.
+func (m *Model) openTimeline() (tea.Model, tea.Cmd) {
+	path, ok := m.selectedArchive()
+	if !ok {
+		return m, nil
+	}
+	m.CurrentState = ShowTimeline
+	m.SelectedID = m.selectedSeriesID()
+	m.Loading = true
+	m.TimelinePath = path
+	m.TimelineFilter = ""
+	m.TimelineGame = 0
+	m.TimelinePrompt = ""
+	return m, tea.Batch(tea.ClearScreen, timelineCmd(path, ""), m.Spinner.Tick)
+}
+
+// handleTimelineMsg displays loaded timeline events.
+func (m *Model) handleTimelineMsg(msg timelineMsg) (tea.Model, tea.Cmd) {
+	m.Loading = false
+	m.Timeline = msg.entries
+	m.TimelineFilter = msg.expr
+	if m.TimelineGame > 0 && !containsGame(m.timelineGames(), m.TimelineGame) {
+		m.TimelineGame = 0
+	}
+	m.setTimelineTable()
+	return m, nil
+}
+
+// handleTimelineKey handles key presses on the timeline screen.
+//
+// The left and right arrows switch between the game tabs, '/' edits the filter expression,
+// 'g' jumps to a game time, 'e' exports the displayed events and Esc goes back to the table.
+// While an input is open, keys edit the input instead.
+//
+// Parameters:
+//   - msg: A tea.KeyMsg representing the key message to be handled.
+//
+// Returns:
+//   - tea.Model: The updated model.
+//   - tea.Cmd: A command to be executed, if any.
+func (m *Model) handleTimelineKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.TimelinePrompt != "" {
+		return m.handleTimelineInput(msg)
+	}
+	if m.Loading {
+		if msg.String() == "ctrl+c" || msg.String() == "q" {
+			return m, tea.Quit
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "esc", "backspace":
+		m.CurrentState = ShowTable
+		m.Timeline = nil
+		return m, tea.ClearScreen
+	case "left", "right", "tab", "shift+tab":
+		tabs := append([]int{0}, m.timelineGames()...)
+		current := 0
+		for i, g := range tabs {
+			if g == m.TimelineGame {
+				current = i
+			}
+		}
+		if msg.String() == "left" || msg.String() == "shift+tab" {
+			current = (current + len(tabs) - 1) % len(tabs)
+		} else {
+			current = (current + 1) % len(tabs)
+		}
+		m.TimelineGame = tabs[current]
+		m.setTimelineTable()
+		return m, nil
+	case "/":
+		m.TimelinePrompt = timelinePromptFilter
+		m.TimelineInput = m.TimelineFilter
+		return m, nil
+	case "g":
+		m.TimelinePrompt = timelinePromptJump
+		m.TimelineInput = ""
+		return m, nil
+	case "e":
+		return m, m.exportTable(timelineHeaders, m.timelineRows())
+	}
+
+	var cmd tea.Cmd
+	m.TimelineTable, cmd = m.TimelineTable.Update(msg)
+	return m, cmd
+}
+
+// handleTimelineInput edits the filter or jump-to-time input of the timeline.
+func (m *Model) handleTimelineInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.TimelinePrompt = ""
+		return m, nil
+	case tea.KeyBackspace:
+		if len(m.TimelineInput) > 0 {
+			runes := []rune(m.TimelineInput)
+			m.TimelineInput = string(runes[:len(runes)-1])
+		}
+		return m, nil
+	case tea.KeySpace:
+		m.TimelineInput += " "
+		return m, nil
+	case tea.KeyRunes:
+		m.TimelineInput += string(msg.Runes)
+		return m, nil
+	case tea.KeyEnter:
+	default:
+		return m, nil
+	}
+
+	prompt := m.TimelinePrompt
+	m.TimelinePrompt = ""
+	if prompt == timelinePromptJump {
+		d, err := filter.ParseGameTime(strings.TrimSpace(m.TimelineInput))
+		if err != nil {
+			m.StatusMsg = err.Error()
+			return m, nil
+		}
+		m.jumpTimeline(d)
+		return m, nil
+	}
+
+	expr := strings.TrimSpace(m.TimelineInput)
+	if _, err := filter.Parse(expr); err != nil {
+		m.StatusMsg = err.Error()
+		return m, nil
+	}
+	m.Loading = true
+	return m, tea.Batch(timelineCmd(m.TimelinePath, expr), m.Spinner.Tick)
+}
+
+// jumpTimeline moves the cursor to the first displayed event at or after a game time.
+func (m *Model) jumpTimeline(d time.Duration) {
+	for i, e := range m.visibleTimeline() {
+		if e.GameNumber > 0 && e.GameTime >= d {
+			m.TimelineTable.SetCursor(i)
+			return
+		}
+	}
+	m.StatusMsg = fmt.Sprintf("No event at or after %s", summary.FormatDuration(d))
+}
+
+// timelineGames returns the numbers of the games with loaded events, in order.
+func (m *Model) timelineGames() []int {
+	var games []int
+	for _, e := range m.Timeline {
+		if e.GameNumber > 0 && !containsGame(games, e.GameNumber) {
+			games = append(games, e.GameNumber)
+		}
+	}
+	return games
+}
+
+// containsGame reports whether a game number is in a list.
+func containsGame(games []int, game int) bool {
+	for _, g := range games {
+		if g == game {
+			return true
+		}
+	}
+	return false
+}
+
+// visibleTimeline returns the loaded events of the selected game tab.
+func (m *Model) visibleTimeline() []TimelineEntry {
+	if m.TimelineGame == 0 {
+		return m.Timeline
+	}
+	var entries []TimelineEntry
+	for _, e := range m.Timeline {
+		if e.GameNumber == m.TimelineGame {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// timelineRows returns the rows of the events of the selected game tab, matching timelineHeaders.
+func (m *Model) timelineRows() [][]string {
+	entries := m.visibleTimeline()
+	rows := make([][]string, len(entries))
+	for i, e := range entries {
+		game, gameTime := "", ""
+		if e.GameNumber > 0 {
+			game = strconv.Itoa(e.GameNumber)
+			gameTime = summary.FormatDuration(e.GameTime)
+		}
+		rows[i] = []string{game, gameTime, e.OccurredAt.Local().Format("2006-01-02 15:04:05"), e.Type, e.Actor, e.Target}
+	}
+	return rows
+}
+
+// setTimelineTable fills the timeline table with the events of the selected game tab.
+func (m *Model) setTimelineTable() {
+	rows := m.timelineRows()
+	m.TimelineTable = newStyledTable(columnsFor(timelineHeaders, rows), toTableRows(rows), 20)
+}
+
+// timelineView renders the timeline screen.
+func (m Model) timelineView() string {
+	if m.Loading {
+		return BaseStyle.Render(fmt.Sprintf("\n\n   %s Reading events, please wait...  \n\n", m.Spinner.View()))
+	}
+
+	tabs := []string{"All"}
+	selected := 0
+	for _, g := range m.timelineGames() {
+		if g == m.TimelineGame {
+			selected = len(tabs)
+		}
+		tabs = append(tabs, fmt.Sprintf("Game %d", g))
+	}
+	for i, tab := range tabs {
+		if i == selected {
+			tabs[i] = "[" + tab + "]"
+		} else {
+			tabs[i] = " " + tab + " "
+		}
+	}
+
+	header := fmt.Sprintf("Series %s  %s\n", m.SelectedID, strings.Join(tabs, " "))
+	if m.TimelineFilter != "" {
+		header += fmt.Sprintf("Filter: %s\n", m.TimelineFilter)
+	}
+	view := header + BaseStyle.Render(m.TimelineTable.View()) +
+		fmt.Sprintf("\n%d events. Press ←/→ to switch games, '/' to filter, 'g' to jump to a time, 'e' to export, or Esc to go back.", len(m.visibleTimeline()))
+	if m.TimelinePrompt != "" {
+		view += fmt.Sprintf("\n%s: %s", m.TimelinePrompt, m.TimelineInput)
+	}
+	if m.StatusMsg != "" {
+		view += "\n" + m.StatusMsg
+	}
+	return view
+}
@@ -0,0 +1,98 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/convert"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/graphql"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/library"
+)
+
+const (
+	// waitOptionPrefix prefixes the ID of download options that wait for a file to become available.
+	waitOptionPrefix = "wait:"
+
+	// waitTimeout is the maximum time spent waiting for files from the user interface.
+	waitTimeout = 6 * time.Hour
+)
+
+// waitProgressMsg reports a check made while waiting for files to become available.
+type waitProgressMsg struct {
+	status string         // status describes the last check.
+	ch     <-chan tea.Msg // ch delivers the following messages of the wait.
+}
+
+// waitForFilesCmd waits for a file of a series to become available and downloads it.
+//
+// This function creates a command that gets a download directory with downloadDirectory, polls the
+// file list of the series with graphql.WaitForFiles and downloads the file once it is ready,
+// recording it in the library. Progress is reported with waitProgressMsg messages and the
+// final download status is reported as a string message, like downloadDataCmd. Nothing is
+// reported when the wait is cancelled through the context.
+//
+// Parameters:
+//   - ctx: The context bounding the wait.
+//   - lib: The library where the downloaded file is recorded.
+//   - series: The metadata of the series to wait for.
+//   - fileID: The file to wait for, either graphql.EventsFileID or a game number.
+//
+// Returns:
+//   - tea.Cmd: A command that starts the wait and returns its first message.
+func waitForFilesCmd(ctx context.Context, lib *library.Library, series library.Series, fileID string) tea.Cmd {
+	return func() tea.Msg {
+		directory, err := downloadDirectory()
+		if err != nil || directory == "" {
+			return "Download cancelled or directory not selected"
+		}
+
+		ch := make(chan tea.Msg)
+		go func() {
+			defer close(ch)
+			err := graphql.WaitForFiles(ctx, series.ID, []string{fileID}, graphql.DefaultBackoff, func(p graphql.WaitProgress) {
+				status := fmt.Sprintf("Check %d: file not available yet", p.Attempt)
+				if p.Err != nil {
+					status = fmt.Sprintf("Check %d: %v", p.Attempt, p.Err)
+				}
+				status += fmt.Sprintf(", next check at %s", time.Now().Add(p.Next).Format("15:04:05"))
+				select {
+				case ch <- waitProgressMsg{status: status, ch: ch}:
+				case <-ctx.Done():
+				}
+			})
+			if ctx.Err() == context.Canceled {
+				return
+			}
+			if err != nil {
+				ch <- fmt.Sprintf("Error waiting for files: %v", err)
+				return
+			}
+
+			path, err := graphql.DownloadFile(series.ID, fileID, directory)
+			if err != nil {
+				ch <- fmt.Sprintf("Error downloading file %s: %v", fileID, err)
+				return
+			}
+			entry, err := recordDownload(lib, series, fileID, path)
+			if err != nil {
+				ch <- fmt.Sprintf("Error recording download in library: %v", err)
+				return
+			}
+			if err := convert.PostDownload(entry); err != nil {
+				ch <- err.Error()
+				return
+			}
+			ch <- "Download complete"
+		}()
+		return listenWaitCmd(ch)()
+	}
+}
+
+// listenWaitCmd returns a command that delivers the next message of a wait.
+func listenWaitCmd(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
+	}
+}
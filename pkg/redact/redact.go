@@ -0,0 +1,365 @@
+// Package redact anonymizes events archives and exported CSV files before they are shared.
+//
+// Team and player names and IDs are replaced with pseudonyms derived from an HMAC-SHA256 of
+// the original value keyed with a secret: the same value always gets the same pseudonym with
+// the same secret, across files and runs, while the original cannot be recovered without the
+// secret. Every replacement is recorded in a mapping that can be kept privately to translate
+// results back.
+package redact
+
+import (
+	"archive/zip"
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Kinds of redacted entities.
+const (
+	Team   = "team"
+	Player = "player"
+)
+
+// Mapping records a replacement made by a Redactor.
+type Mapping struct {
+	Kind      string `json:"kind"`      // Kind is either Team or Player.
+	Field     string `json:"field"`     // Field is either "id" or "name".
+	Original  string `json:"original"`  // Original is the original value.
+	Pseudonym string `json:"pseudonym"` // Pseudonym is the value replacing it.
+}
+
+// Redactor replaces team and player identities with stable pseudonyms.
+type Redactor struct {
+	Drop []string // Drop are the names of the fields removed from events, at any depth.
+
+	secret   []byte
+	mu       sync.Mutex
+	mappings map[Mapping]struct{}
+}
+
+// New creates a Redactor.
+//
+// Parameters:
+//   - secret: The key of the pseudonyms. It must be kept private and reused to get the same pseudonyms.
+//   - drop: The names of the fields removed from events, at any depth.
+//
+// Returns:
+//   - *Redactor: The redactor.
+//   - error: An error if the secret is empty.
+func New(secret string, drop []string) (*Redactor, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("a secret is required to redact data")
+	}
+	return &Redactor{Drop: drop, secret: []byte(secret), mappings: map[Mapping]struct{}{}}, nil
+}
+
+// ID returns the pseudonym of the ID of a team or a player, e.g. "team-1f3a9c0b7d2e".
+func (r *Redactor) ID(kind, id string) string {
+	if id == "" {
+		return ""
+	}
+	return r.record(kind, "id", id, kind+"-"+r.hash(kind, "id", id))
+}
+
+// Name returns the pseudonym of the name of a team or a player, e.g. "Team 1f3a9c".
+func (r *Redactor) Name(kind, name string) string {
+	if name == "" {
+		return ""
+	}
+	label := "Team"
+	if kind == Player {
+		label = "Player"
+	}
+	return r.record(kind, "name", name, label+" "+r.hash(kind, "name", name)[:6])
+}
+
+// hash returns the keyed hash of a value, in hexadecimal.
+func (r *Redactor) hash(kind, field, value string) string {
+	mac := hmac.New(sha256.New, r.secret)
+	mac.Write([]byte(kind + "\x00" + field + "\x00" + value))
+	return hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+// record adds a replacement to the mapping and returns the pseudonym.
+func (r *Redactor) record(kind, field, original, pseudonym string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mappings[Mapping{Kind: kind, Field: field, Original: original, Pseudonym: pseudonym}] = struct{}{}
+	return pseudonym
+}
+
+// Mappings returns the replacements made so far, sorted by kind, field and original value.
+func (r *Redactor) Mappings() []Mapping {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	mappings := make([]Mapping, 0, len(r.mappings))
+	for m := range r.mappings {
+		mappings = append(mappings, m)
+	}
+	sort.Slice(mappings, func(i, j int) bool {
+		a, b := mappings[i], mappings[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		return a.Original < b.Original
+	})
+	return mappings
+}
+
+// WriteMapping writes the replacements made so far to a JSON file.
+func (r *Redactor) WriteMapping(path string) error {
+	data, err := json.MarshalIndent(r.Mappings(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding mapping: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("error writing mapping: %v", err)
+	}
+	return nil
+}
+
+// Document redacts a decoded JSON document in place.
+//
+// Objects with a "type" of "team" or "player", such as the actor and the target of events, and
+// the objects of "teams" and "players" lists, such as in series states, have their "id" and
+// "name" replaced, including in their "state" and "stateDelta". Fields named "teamId" or
+// "playerId" are replaced too, and the fields listed in Drop are removed.
+func (r *Redactor) Document(doc any) {
+	r.walk(doc, "")
+}
+
+// walk redacts a JSON value, knowing the kind of entity it describes, if any.
+func (r *Redactor) walk(value any, kind string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for _, field := range r.Drop {
+			delete(v, field)
+		}
+		if t, ok := v["type"].(string); ok && (t == Team || t == Player) {
+			kind = t
+		}
+		if kind != "" {
+			if id, ok := v["id"].(string); ok {
+				v["id"] = r.ID(kind, id)
+			}
+			if name, ok := v["name"].(string); ok {
+				v["name"] = r.Name(kind, name)
+			}
+		}
+		for key, child := range v {
+			switch key {
+			case "teamId":
+				if s, ok := child.(string); ok {
+					v[key] = r.ID(Team, s)
+				}
+			case "playerId":
+				if s, ok := child.(string); ok {
+					v[key] = r.ID(Player, s)
+				}
+			case "teams":
+				r.walk(child, Team)
+			case "players":
+				r.walk(child, Player)
+			case "state", "stateDelta":
+				r.walk(child, kind)
+			default:
+				r.walk(child, "")
+			}
+		}
+	case []any:
+		for _, child := range v {
+			r.walk(child, kind)
+		}
+	}
+}
+
+// Archive rewrites an events archive with redacted events.
+//
+// Every JSON lines file of the archive is rewritten line by line with the same name; other
+// files are copied unchanged.
+//
+// Parameters:
+//   - in: The path of the events archive.
+//   - out: The path of the redacted archive.
+//
+// Returns:
+//   - error: An error if the archive cannot be read or written.
+func (r *Redactor) Archive(in, out string) error {
+	zr, err := zip.OpenReader(in)
+	if err != nil {
+		return fmt.Errorf("error opening archive: %v", err)
+	}
+	defer zr.Close()
+
+	file, err := os.Create(out)
+	if err != nil {
+		return fmt.Errorf("error creating file: %v", err)
+	}
+	defer file.Close()
+	zw := zip.NewWriter(file)
+
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.Name, Method: zip.Deflate, Modified: f.Modified})
+		if err != nil {
+			return fmt.Errorf("error writing archive: %v", err)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("error reading %s: %v", f.Name, err)
+		}
+		if isJSONFile(f.Name) {
+			err = r.JSON(rc, w)
+		} else {
+			_, err = io.Copy(w, rc)
+		}
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("error redacting %s: %v", f.Name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("error writing archive: %v", err)
+	}
+	return file.Close()
+}
+
+// isJSONFile reports whether a file of an archive holds JSON events.
+func isJSONFile(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".jsonl") || strings.HasSuffix(lower, ".json") || strings.HasSuffix(lower, ".ndjson")
+}
+
+// JSON redacts JSON documents read from r, either one per line or a single document, and
+// writes them to w one per line.
+func (r *Redactor) JSON(in io.Reader, out io.Writer) error {
+	dec := json.NewDecoder(bufio.NewReader(in))
+	dec.UseNumber()
+	w := bufio.NewWriter(out)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for {
+		var doc any
+		if err := dec.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		r.Document(doc)
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// CSV redacts the columns of a CSV file holding team and player names.
+//
+// Parameters:
+//   - in: The CSV file, with a header row.
+//   - out: The destination of the redacted CSV file.
+//   - columns: The kind of entity of the columns to redact, by header, e.g. {"Team One": Team},
+//     or nil to use DefaultColumns. Columns whose header ends with "ID" get ID pseudonyms,
+//     others name pseudonyms.
+//   - drop: The headers of the columns to remove.
+//
+// Returns:
+//   - error: An error if the file cannot be read or written.
+func (r *Redactor) CSV(in io.Reader, out io.Writer, columns map[string]string, drop []string) error {
+	reader := csv.NewReader(in)
+	reader.FieldsPerRecord = -1
+	writer := csv.NewWriter(out)
+
+	headers, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error reading CSV: %v", err)
+	}
+	if columns == nil {
+		columns = DefaultColumns(headers)
+	}
+	var keep []int
+	for i, h := range headers {
+		if !containsFold(drop, h) {
+			keep = append(keep, i)
+		}
+	}
+
+	row := make([]string, 0, len(keep))
+	for _, i := range keep {
+		row = append(row, headers[i])
+	}
+	if err := writer.Write(row); err != nil {
+		return fmt.Errorf("error writing CSV: %v", err)
+	}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("error reading CSV: %v", err)
+		}
+		row = row[:0]
+		for _, i := range keep {
+			if i >= len(record) {
+				row = append(row, "")
+				continue
+			}
+			value := record[i]
+			if kind, ok := columns[headers[i]]; ok {
+				if strings.HasSuffix(strings.ToLower(headers[i]), "id") {
+					value = r.ID(kind, value)
+				} else {
+					value = r.Name(kind, value)
+				}
+			}
+			row = append(row, value)
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("error writing CSV: %v", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// DefaultColumns returns the columns of a CSV header holding team and player identities: those
+// whose header mentions a team, a player or a winner.
+func DefaultColumns(headers []string) map[string]string {
+	columns := map[string]string{}
+	for _, h := range headers {
+		lower := strings.ToLower(h)
+		switch {
+		case strings.Contains(lower, "player"):
+			columns[h] = Player
+		case strings.Contains(lower, "team") || strings.Contains(lower, "winner"):
+			columns[h] = Team
+		}
+	}
+	return columns
+}
+
+// containsFold reports whether a list contains a value, ignoring case.
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
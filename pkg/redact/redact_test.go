@@ -0,0 +1,72 @@
+package redact
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const testEvents = `{"id":"tx-1","seriesId":"2620066","events":[{"id":"ev-1","type":"player-killed-player","actor":{"type":"player","id":"p1","state":{"name":"Faker","teamId":"t1","ip":"10.0.0.1"}},"target":{"type":"player","id":"p2"},"seriesState":{"teams":[{"id":"t1","name":"T1","players":[{"id":"p1","name":"Faker"}]}]}}]}
+`
+
+func TestJSON(t *testing.T) {
+	r, err := New("secret", []string{"ip"})
+	if err != nil {
+		t.Fatalf("Failed to create redactor: %v", err)
+	}
+	var out bytes.Buffer
+	if err := r.JSON(strings.NewReader(testEvents), &out); err != nil {
+		t.Fatalf("Failed to redact events: %v", err)
+	}
+
+	redacted := out.String()
+	for _, original := range []string{`"p1"`, `"p2"`, `"t1"`, "Faker", `"T1"`, "10.0.0.1"} {
+		if strings.Contains(redacted, original) {
+			t.Fatalf("Expected %s to be redacted, but got %s", original, redacted)
+		}
+	}
+	for _, pseudonym := range []string{r.ID(Player, "p1"), r.ID(Team, "t1"), r.Name(Player, "Faker"), r.Name(Team, "T1")} {
+		if strings.Count(redacted, `"`+pseudonym+`"`) < 1 {
+			t.Fatalf("Expected pseudonym %s in %s", pseudonym, redacted)
+		}
+	}
+	if !strings.Contains(redacted, `"2620066"`) {
+		t.Fatalf("Expected the series ID to be kept, but got %s", redacted)
+	}
+}
+
+func TestStablePseudonyms(t *testing.T) {
+	a, _ := New("secret", nil)
+	b, _ := New("secret", nil)
+	c, _ := New("other", nil)
+	if a.ID(Team, "t1") != b.ID(Team, "t1") {
+		t.Fatalf("Expected the same pseudonym with the same secret, but got %s and %s", a.ID(Team, "t1"), b.ID(Team, "t1"))
+	}
+	if a.ID(Team, "t1") == c.ID(Team, "t1") {
+		t.Fatalf("Expected different pseudonyms with different secrets, but got %s", a.ID(Team, "t1"))
+	}
+	if a.ID(Team, "1") == a.ID(Player, "1") {
+		t.Fatalf("Expected different pseudonyms for a team and a player with the same ID")
+	}
+	if _, err := New("", nil); err == nil {
+		t.Fatalf("Expected an error without a secret")
+	}
+}
+
+func TestCSV(t *testing.T) {
+	r, _ := New("secret", nil)
+	in := "Series ID,Team One,Team Two,Winner,Tournament\n1,T1,G2,T1,Worlds\n"
+	var out bytes.Buffer
+	if err := r.CSV(strings.NewReader(in), &out, nil, []string{"tournament"}); err != nil {
+		t.Fatalf("Failed to redact CSV: %v", err)
+	}
+
+	t1, g2 := r.Name(Team, "T1"), r.Name(Team, "G2")
+	expected := "Series ID,Team One,Team Two,Winner\n1," + t1 + "," + g2 + "," + t1 + "\n"
+	if out.String() != expected {
+		t.Fatalf("Expected %q, but got %q", expected, out.String())
+	}
+	if len(r.Mappings()) != 2 {
+		t.Fatalf("Expected 2 mappings, but got %d", len(r.Mappings()))
+	}
+}
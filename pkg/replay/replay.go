@@ -0,0 +1,176 @@
+// Package replay reconstructs the state of a series at any point in time by replaying its events.
+//
+// GRID events carry either the full series state after the event or only the change of the
+// series state. A Replayer applies both kinds in order, so that the scores, gold, kills,
+// objectives, per-player statistics and rounds of every game are known after each event.
+// Snapshots of the reconstructed state can be taken at game times ("14:00" in every game),
+// at timestamps, or at fixed game time intervals, and exported as CSV or JSON.
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+)
+
+// Replayer maintains the series state reconstructed from the events applied so far.
+type Replayer struct {
+	SeriesID   string        // SeriesID is the ID of the series.
+	Time       time.Time     // Time is the time of the last event applied.
+	GameNumber int           // GameNumber is the number of the game in progress, 0 between games.
+	GameTime   time.Duration // GameTime is the time elapsed since the start of the game in progress.
+
+	raw       json.RawMessage // raw is the last full series state, while no delta was applied to it.
+	doc       map[string]any  // doc is the series state with the deltas applied, once decoded.
+	games     int             // games is the number of games started so far.
+	gameStart time.Time       // gameStart is the time the game in progress started.
+	inGame    bool            // inGame indicates whether a game is in progress.
+}
+
+// New creates a Replayer with an empty series state.
+func New() *Replayer {
+	return &Replayer{}
+}
+
+// Apply applies an event to the reconstructed state.
+//
+// Full series states replace the reconstructed state, while state deltas are merged into it:
+// objects are merged field by field and lists of objects with an "id" are merged element by
+// element, matched by ID. Full states are only decoded when a delta follows them or when the
+// state is read, so replaying archives that carry a full state with every event stays cheap.
+//
+// Parameters:
+//   - env: The next event of the series.
+//
+// Returns:
+//   - error: An error if the state or the state delta of the event cannot be decoded.
+func (r *Replayer) Apply(env events.Envelope) error {
+	r.advance(env)
+	if len(env.SeriesState) > 0 {
+		r.raw = env.SeriesState
+		r.doc = nil
+		return nil
+	}
+	if len(env.SeriesStateDelta) == 0 {
+		return nil
+	}
+
+	var delta map[string]any
+	if err := json.Unmarshal(env.SeriesStateDelta, &delta); err != nil {
+		return fmt.Errorf("error decoding series state delta of event %s: %v", env.ID, err)
+	}
+	if r.doc == nil {
+		r.doc = map[string]any{}
+		if len(r.raw) > 0 {
+			if err := json.Unmarshal(r.raw, &r.doc); err != nil {
+				return fmt.Errorf("error decoding series state: %v", err)
+			}
+			r.raw = nil
+		}
+	}
+	merge(r.doc, delta)
+	return nil
+}
+
+// advance updates the time and the game context of the replay with the next event.
+func (r *Replayer) advance(env events.Envelope) {
+	r.SeriesID = env.SeriesID
+	r.Time = env.OccurredAt
+	r.GameNumber, r.GameTime = r.context(env)
+	switch env.Type {
+	case "series-started-game":
+		r.games++
+		r.inGame = true
+		r.gameStart = env.OccurredAt
+	case "series-ended-game":
+		r.inGame = false
+	}
+}
+
+// context returns the game number and the game time of an event, before it is applied.
+func (r *Replayer) context(env events.Envelope) (int, time.Duration) {
+	switch {
+	case env.Type == "series-started-game":
+		return r.games + 1, 0
+	case r.inGame:
+		return r.games, env.OccurredAt.Sub(r.gameStart)
+	}
+	return 0, 0
+}
+
+// State decodes the reconstructed series state.
+//
+// Returns:
+//   - *events.SeriesState: The series state, or nil if no event carried a state yet.
+//   - error: An error if the state cannot be decoded.
+func (r *Replayer) State() (*events.SeriesState, error) {
+	data := r.raw
+	if r.doc != nil {
+		var err error
+		if data, err = json.Marshal(r.doc); err != nil {
+			return nil, fmt.Errorf("error encoding series state: %v", err)
+		}
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var state events.SeriesState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("error decoding series state: %v", err)
+	}
+	return &state, nil
+}
+
+// merge merges a state delta into a state.
+func merge(dst, delta map[string]any) {
+	for k, v := range delta {
+		switch dv := v.(type) {
+		case map[string]any:
+			if cur, ok := dst[k].(map[string]any); ok {
+				merge(cur, dv)
+				continue
+			}
+		case []any:
+			if cur, ok := dst[k].([]any); ok && identified(dv) {
+				dst[k] = mergeList(cur, dv)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+// identified reports whether every element of a list is an object with an ID.
+func identified(list []any) bool {
+	for _, v := range list {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return false
+		}
+		if _, ok := obj["id"]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeList merges a list of objects with an ID into another, appending the new objects.
+func mergeList(dst, delta []any) []any {
+	for _, v := range delta {
+		obj := v.(map[string]any)
+		found := false
+		for _, cur := range dst {
+			if c, ok := cur.(map[string]any); ok && c["id"] == obj["id"] {
+				merge(c, obj)
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, obj)
+		}
+	}
+	return dst
+}
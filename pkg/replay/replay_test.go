@@ -0,0 +1,62 @@
+package replay
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/filter"
+)
+
+const testEvents = `{"id":"tx-1","sequenceNumber":1,"occurredAt":"2024-05-10T12:00:00Z","seriesId":"2620066","events":[{"id":"ev-1","type":"series-started-game","target":{"type":"game","id":"g1"},"seriesState":{"id":"2620066","games":[{"id":"g1","sequenceNumber":1,"started":true,"map":{"name":"Summoner's Rift"},"teams":[{"id":"t1","name":"Team 1","players":[{"id":"p1","name":"Player 1"}]},{"id":"t2","name":"Team 2"}]}]}}]}
+{"id":"tx-2","sequenceNumber":2,"occurredAt":"2024-05-10T12:05:00Z","seriesId":"2620066","events":[{"id":"ev-2","type":"player-killed-player","actor":{"type":"player","id":"p1"},"seriesStateDelta":{"games":[{"id":"g1","teams":[{"id":"t1","kills":1,"netWorth":5000,"players":[{"id":"p1","kills":1}]}]}]}}]}
+{"id":"tx-3","sequenceNumber":3,"occurredAt":"2024-05-10T12:15:00Z","seriesId":"2620066","events":[{"id":"ev-3","type":"player-killed-player","actor":{"type":"player","id":"p1"},"seriesStateDelta":{"games":[{"id":"g1","teams":[{"id":"t1","kills":3,"objectives":[{"id":"slayDragon","type":"slayDragon","completionCount":1}]}]}]}}]}
+{"id":"tx-4","sequenceNumber":4,"occurredAt":"2024-05-10T12:30:00Z","seriesId":"2620066","events":[{"id":"ev-4","type":"series-ended-game","target":{"type":"game","id":"g1"},"seriesStateDelta":{"games":[{"id":"g1","finished":true}]}}]}
+`
+
+func TestSnapshots(t *testing.T) {
+	at, err := filter.ParseBound("10:00")
+	if err != nil {
+		t.Fatalf("Failed to parse bound: %v", err)
+	}
+	end, _ := filter.ParseBound("2024-05-10T12:40:00Z")
+
+	seq := events.Flatten(events.ReadTransactions(strings.NewReader(testEvents)))
+	snapshots, err := Snapshots(seq, Options{At: []filter.Bound{at, end}, Every: 10 * time.Minute})
+	if err != nil {
+		t.Fatalf("Failed to take snapshots: %v", err)
+	}
+	if len(snapshots) != 3 {
+		t.Fatalf("Expected 3 snapshots, but got %d", len(snapshots))
+	}
+
+	first := snapshots[0].Game()
+	if snapshots[0].GameTime != 10*time.Minute || first.Teams[0].Kills != 1 || first.Teams[0].NetWorth != 5000 {
+		t.Fatalf("Unexpected state at 10:00: %+v", first.Teams[0])
+	}
+	if first.Teams[0].Name != "Team 1" || len(first.Teams) != 2 || first.Teams[0].Players[0].Name != "Player 1" {
+		t.Fatalf("Expected deltas to be merged into the state, but got %+v", first.Teams)
+	}
+
+	last := snapshots[2]
+	if last.GameNumber != 0 || !last.Game().Finished || last.Game().Teams[0].Kills != 3 {
+		t.Fatalf("Unexpected final snapshot %+v", last.Game())
+	}
+
+	rows := TeamRows(snapshots)
+	if len(rows) != 6 || rows[2][1] != "1" || rows[2][2] != "20:00" || rows[2][11] != "slayDragon 1" {
+		t.Fatalf("Unexpected team rows %v", rows)
+	}
+	players := PlayerRows(snapshots)
+	if len(players) != 3 || players[0][6] != "1" {
+		t.Fatalf("Unexpected player rows %v", players)
+	}
+}
+
+func TestSnapshotsWithoutTimes(t *testing.T) {
+	seq := events.Flatten(events.ReadTransactions(strings.NewReader(testEvents)))
+	if _, err := Snapshots(seq, Options{}); err == nil {
+		t.Fatalf("Expected an error without snapshot times, but got none")
+	}
+}
@@ -0,0 +1,271 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/filter"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/summary"
+)
+
+// Snapshot is the reconstructed series state at a point in time.
+type Snapshot struct {
+	Time       time.Time           `json:"time"`       // Time is the time of the snapshot.
+	SeriesID   string              `json:"seriesId"`   // SeriesID is the ID of the series.
+	GameNumber int                 `json:"gameNumber"` // GameNumber is the number of the game in progress, 0 between games.
+	GameTime   time.Duration       `json:"-"`          // GameTime is the time elapsed since the start of the game in progress.
+	State      *events.SeriesState `json:"state"`      // State is the series state after the last event before the snapshot.
+}
+
+// Options selects the points in time of the snapshots.
+type Options struct {
+	At    []filter.Bound // At are game times, taken in every game, or timestamps.
+	Every time.Duration  // Every takes a snapshot at each multiple of this game time in every game, if positive.
+}
+
+// TeamHeaders are the headers of the rows returned by TeamRows.
+var TeamHeaders = []string{"Time", "Game", "Game Time", "Map", "Round", "Team", "Score", "Kills", "Deaths", "Net Worth", "Money", "Objectives"}
+
+// PlayerHeaders are the headers of the rows returned by PlayerRows.
+var PlayerHeaders = []string{"Time", "Game", "Game Time", "Team", "Player", "Character", "Kills", "Deaths", "Assists", "Net Worth", "Money", "Alive"}
+
+// MarshalJSON encodes the snapshot with its game time formatted as minutes and seconds.
+func (s Snapshot) MarshalJSON() ([]byte, error) {
+	type plain Snapshot
+	return json.Marshal(struct {
+		plain
+		GameTime string `json:"gameTime"`
+	}{plain(s), summary.FormatDuration(s.GameTime)})
+}
+
+// Snapshots replays the events of a series and takes snapshots of its state.
+//
+// A snapshot at a game time holds the state after the last event that occurred at or before
+// that time in a game, and is taken in every game that lasts longer. A snapshot at a timestamp
+// holds the state after the last event that occurred at or before it. Snapshots are returned in
+// chronological order.
+//
+// Parameters:
+//   - seq: An iterator over the events of the series, in order.
+//   - opts: The points in time of the snapshots.
+//
+// Returns:
+//   - []Snapshot: The snapshots taken.
+//   - error: An error if no point in time is selected or the events cannot be replayed.
+func Snapshots(seq iter.Seq2[events.Envelope, error], opts Options) ([]Snapshot, error) {
+	var absolute []time.Time
+	sched := schedule{every: opts.Every}
+	for _, b := range opts.At {
+		if b.Relative {
+			sched.at = append(sched.at, b.Offset)
+		} else {
+			absolute = append(absolute, b.Time)
+		}
+	}
+	if len(absolute) == 0 && len(sched.at) == 0 && opts.Every <= 0 {
+		return nil, fmt.Errorf("no snapshot time selected")
+	}
+	sort.Slice(absolute, func(i, j int) bool { return absolute[i].Before(absolute[j]) })
+	sort.Slice(sched.at, func(i, j int) bool { return sched.at[i] < sched.at[j] })
+
+	r := New()
+	var snapshots []Snapshot
+	take := func(t time.Time, game int, gameTime time.Duration) error {
+		state, err := r.State()
+		if err != nil || state == nil {
+			return err
+		}
+		snapshots = append(snapshots, Snapshot{Time: t, SeriesID: r.SeriesID, GameNumber: game, GameTime: gameTime, State: state})
+		return nil
+	}
+	takeAbsolute := func(t time.Time) error {
+		if r.inGame {
+			return take(t, r.games, t.Sub(r.gameStart))
+		}
+		return take(t, 0, 0)
+	}
+
+	for env, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		if env.Type == "series-started-game" {
+			sched.reset()
+		} else if game, gameTime := r.context(env); game > 0 {
+			for d, ok := sched.peek(); ok && d < gameTime; d, ok = sched.peek() {
+				if err := take(r.gameStart.Add(d), game, d); err != nil {
+					return nil, err
+				}
+				sched.pop()
+			}
+		}
+		for len(absolute) > 0 && absolute[0].Before(env.OccurredAt) {
+			if err := takeAbsolute(absolute[0]); err != nil {
+				return nil, err
+			}
+			absolute = absolute[1:]
+		}
+		if err := r.Apply(env); err != nil {
+			return nil, err
+		}
+	}
+	for _, t := range absolute {
+		if err := takeAbsolute(t); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool { return snapshots[i].Time.Before(snapshots[j].Time) })
+	return snapshots, nil
+}
+
+// schedule yields the game times of the snapshots of a game in increasing order.
+type schedule struct {
+	at    []time.Duration // at are the game times given explicitly, sorted.
+	every time.Duration   // every is the interval between snapshots, if positive.
+	i     int             // i is the index of the next explicit game time.
+	next  time.Duration   // next is the next multiple of the interval.
+}
+
+// reset restarts the schedule for a new game.
+func (s *schedule) reset() {
+	s.i = 0
+	s.next = s.every
+}
+
+// peek returns the next game time of the schedule, if any.
+func (s *schedule) peek() (time.Duration, bool) {
+	ok := false
+	var d time.Duration
+	if s.i < len(s.at) {
+		d, ok = s.at[s.i], true
+	}
+	if s.every > 0 && (!ok || s.next < d) {
+		d, ok = s.next, true
+	}
+	return d, ok
+}
+
+// pop moves the schedule past its next game time.
+func (s *schedule) pop() {
+	d, _ := s.peek()
+	for s.i < len(s.at) && s.at[s.i] <= d {
+		s.i++
+	}
+	if s.every > 0 && s.next <= d {
+		s.next += s.every
+	}
+}
+
+// Game returns the state of the game of the snapshot: the game in progress, or the last game
+// started between games. It returns nil if no game has started.
+func (s Snapshot) Game() *events.GameState {
+	if s.State == nil {
+		return nil
+	}
+	var last *events.GameState
+	for i := range s.State.Games {
+		g := &s.State.Games[i]
+		number := g.SequenceNumber
+		if number == 0 {
+			number = i + 1
+		}
+		if s.GameNumber > 0 && number == s.GameNumber {
+			return g
+		}
+		if g.Started {
+			last = g
+		}
+	}
+	return last
+}
+
+// Round returns the number of the last round started in a game, or 0 if the game has no rounds.
+func Round(g *events.GameState) int {
+	round := 0
+	for _, s := range g.Segments {
+		if s.Started && s.SequenceNumber > round {
+			round = s.SequenceNumber
+		}
+	}
+	return round
+}
+
+// TeamRows returns one row per snapshot and team of its game, matching TeamHeaders.
+func TeamRows(snapshots []Snapshot) [][]string {
+	var rows [][]string
+	for _, s := range snapshots {
+		g := s.Game()
+		if g == nil {
+			continue
+		}
+		round := ""
+		if n := Round(g); n > 0 {
+			round = strconv.Itoa(n)
+		}
+		for _, t := range g.Teams {
+			objectives := map[string]int{}
+			for _, o := range t.Objectives {
+				name := o.Type
+				if name == "" {
+					name = o.ID
+				}
+				objectives[name] += o.CompletionCount
+			}
+			rows = append(rows, append(s.prefix(),
+				g.Map.Name,
+				round,
+				t.Name,
+				strconv.Itoa(t.Score),
+				strconv.Itoa(t.Kills),
+				strconv.Itoa(t.Deaths),
+				strconv.Itoa(t.NetWorth),
+				strconv.Itoa(t.Money),
+				summary.FormatObjectives(objectives),
+			))
+		}
+	}
+	return rows
+}
+
+// PlayerRows returns one row per snapshot and player of its game, matching PlayerHeaders.
+func PlayerRows(snapshots []Snapshot) [][]string {
+	var rows [][]string
+	for _, s := range snapshots {
+		g := s.Game()
+		if g == nil {
+			continue
+		}
+		for _, t := range g.Teams {
+			for _, p := range t.Players {
+				rows = append(rows, append(s.prefix(),
+					t.Name,
+					p.Name,
+					p.Character.Name,
+					strconv.Itoa(p.Kills),
+					strconv.Itoa(p.Deaths),
+					strconv.Itoa(p.KillAssistsGiven),
+					strconv.Itoa(p.NetWorth),
+					strconv.Itoa(p.Money),
+					strconv.FormatBool(p.Alive),
+				))
+			}
+		}
+	}
+	return rows
+}
+
+// prefix returns the time, game and game time columns of the rows of a snapshot.
+func (s Snapshot) prefix() []string {
+	game, gameTime := "", ""
+	if s.GameNumber > 0 {
+		game = strconv.Itoa(s.GameNumber)
+		gameTime = summary.FormatDuration(s.GameTime)
+	}
+	return []string{s.Time.UTC().Format(time.RFC3339), game, gameTime}
+}
@@ -0,0 +1,317 @@
+// Package rofl reads the metadata of League of Legends replay files (.rofl).
+//
+// A ROFL file starts with the "RIOT" magic and embeds a JSON document describing the game:
+// its length, the client version and the end of game statistics of every player. Replays
+// recorded by older clients locate this document through a binary header that follows the
+// file signature, while newer clients append it at the end of the file, followed by its length.
+// Both layouts are supported, without needing the game client.
+package rofl
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// magic is the prefix of every ROFL file.
+	magic = "RIOT"
+
+	// headerOffset is the offset of the binary header of older ROFL files, after the magic and the signature.
+	headerOffset = 262
+
+	// headerSize is the size of the binary header of older ROFL files.
+	headerSize = 26
+
+	// maxMetadataSize bounds the size of the metadata document, to reject corrupted lengths.
+	maxMetadataSize = 16 << 20
+)
+
+// Metadata is the metadata of a replay.
+type Metadata struct {
+	GameLength      int64    `json:"gameLength"`      // GameLength is the length of the game in milliseconds.
+	GameVersion     string   `json:"gameVersion"`     // GameVersion is the version of the game client, e.g. "14.9.584.6824".
+	Patch           string   `json:"patch"`           // Patch is the patch of the game, e.g. "14.9".
+	LastGameChunkID int      `json:"lastGameChunkId"` // LastGameChunkID is the ID of the last chunk of the replay.
+	LastKeyFrameID  int      `json:"lastKeyFrameId"`  // LastKeyFrameID is the ID of the last key frame of the replay.
+	Players         []Player `json:"players"`         // Players are the end of game statistics of the players.
+}
+
+// Player holds the end of game statistics of a player.
+type Player struct {
+	Name        string            `json:"name"`        // Name is the name of the player, with the Riot ID tag line if known.
+	Champion    string            `json:"champion"`    // Champion is the champion played.
+	Side        string            `json:"side"`        // Side is "Blue" or "Red".
+	Position    string            `json:"position"`    // Position is the position played, e.g. "MIDDLE".
+	Win         bool              `json:"win"`         // Win indicates whether the team of the player won.
+	Level       int               `json:"level"`       // Level is the champion level at the end of the game.
+	Kills       int               `json:"kills"`       // Kills is the number of kills of the player.
+	Deaths      int               `json:"deaths"`      // Deaths is the number of deaths of the player.
+	Assists     int               `json:"assists"`     // Assists is the number of assists of the player.
+	CreepScore  int               `json:"creepScore"`  // CreepScore is the number of minions and monsters killed.
+	Gold        int               `json:"gold"`        // Gold is the gold earned.
+	Damage      int               `json:"damage"`      // Damage is the damage dealt to champions.
+	VisionScore int               `json:"visionScore"` // VisionScore is the vision score.
+	Stats       map[string]string `json:"stats"`       // Stats are all the statistics recorded for the player, as found in the replay.
+}
+
+// PlayerHeaders are the headers of the rows returned by PlayerRows.
+var PlayerHeaders = []string{"Side", "Player", "Champion", "Position", "Result", "Level", "Kills", "Deaths", "Assists", "CS", "Gold", "Damage", "Vision"}
+
+// ParseFile reads the metadata of a replay file.
+//
+// Parameters:
+//   - path: The path of the replay downloaded with graphql.DownloadGame.
+//
+// Returns:
+//   - *Metadata: The metadata of the replay.
+//   - error: An error if the file cannot be read or is not a replay.
+func ParseFile(path string) (*Metadata, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening replay: %v", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("error reading replay: %v", err)
+	}
+	return Parse(file, info.Size())
+}
+
+// Parse reads the metadata of a replay.
+//
+// Parameters:
+//   - r: The content of the replay.
+//   - size: The size of the replay in bytes.
+//
+// Returns:
+//   - *Metadata: The metadata of the replay.
+//   - error: An error if the content is not a replay or its metadata cannot be decoded.
+func Parse(r io.ReaderAt, size int64) (*Metadata, error) {
+	prefix := make([]byte, len(magic))
+	if _, err := r.ReadAt(prefix, 0); err != nil || string(prefix) != magic {
+		return nil, fmt.Errorf("not a ROFL replay file")
+	}
+
+	if data, ok := headerMetadata(r, size); ok {
+		if m, err := decode(data); err == nil {
+			return m, nil
+		}
+	}
+	data, err := trailerMetadata(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return decode(data)
+}
+
+// headerMetadata reads the metadata located by the binary header of older replays.
+func headerMetadata(r io.ReaderAt, size int64) ([]byte, bool) {
+	header := make([]byte, headerSize)
+	if _, err := r.ReadAt(header, headerOffset); err != nil {
+		return nil, false
+	}
+	offset := int64(binary.LittleEndian.Uint32(header[6:10]))
+	length := int64(binary.LittleEndian.Uint32(header[10:14]))
+	if length == 0 || length > maxMetadataSize || offset < headerOffset+headerSize || offset+length > size {
+		return nil, false
+	}
+	data := make([]byte, length)
+	if _, err := r.ReadAt(data, offset); err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// trailerMetadata reads the metadata appended at the end of newer replays.
+func trailerMetadata(r io.ReaderAt, size int64) ([]byte, error) {
+	if size < int64(len(magic))+4 {
+		return nil, fmt.Errorf("replay file is truncated")
+	}
+	trailer := make([]byte, 4)
+	if _, err := r.ReadAt(trailer, size-4); err != nil {
+		return nil, fmt.Errorf("error reading replay: %v", err)
+	}
+	length := int64(binary.LittleEndian.Uint32(trailer))
+	if length == 0 || length > maxMetadataSize || length > size-4-int64(len(magic)) {
+		return nil, fmt.Errorf("no metadata found in replay")
+	}
+	data := make([]byte, length)
+	if _, err := r.ReadAt(data, size-4-length); err != nil {
+		return nil, fmt.Errorf("error reading replay metadata: %v", err)
+	}
+	return data, nil
+}
+
+// decode decodes the metadata document of a replay.
+func decode(data []byte) (*Metadata, error) {
+	var raw struct {
+		GameLength      int64           `json:"gameLength"`
+		GameVersion     string          `json:"gameVersion"`
+		LastGameChunkID int             `json:"lastGameChunkId"`
+		LastKeyFrameID  int             `json:"lastKeyFrameId"`
+		StatsJSON       json.RawMessage `json:"statsJson"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("error decoding replay metadata: %v", err)
+	}
+
+	// The statistics are usually a JSON document encoded as a string.
+	stats := []byte(raw.StatsJSON)
+	if len(stats) > 0 && stats[0] == '"' {
+		var s string
+		if err := json.Unmarshal(stats, &s); err != nil {
+			return nil, fmt.Errorf("error decoding replay statistics: %v", err)
+		}
+		stats = []byte(s)
+	}
+	var players []map[string]any
+	if len(stats) > 0 {
+		if err := json.Unmarshal(stats, &players); err != nil {
+			return nil, fmt.Errorf("error decoding replay statistics: %v", err)
+		}
+	}
+
+	m := &Metadata{
+		GameLength:      raw.GameLength,
+		GameVersion:     raw.GameVersion,
+		Patch:           patch(raw.GameVersion),
+		LastGameChunkID: raw.LastGameChunkID,
+		LastKeyFrameID:  raw.LastKeyFrameID,
+	}
+	for _, values := range players {
+		m.Players = append(m.Players, newPlayer(values))
+	}
+	return m, nil
+}
+
+// patch returns the major and minor parts of a client version.
+func patch(version string) string {
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 {
+		return version
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// newPlayer builds a player from the statistics recorded in a replay.
+func newPlayer(values map[string]any) Player {
+	stats := make(map[string]string, len(values))
+	for k, v := range values {
+		switch v := v.(type) {
+		case string:
+			stats[k] = v
+		case float64:
+			stats[k] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			stats[k] = strconv.FormatBool(v)
+		}
+	}
+	number := func(keys ...string) int {
+		total := 0
+		for _, k := range keys {
+			n, _ := strconv.Atoi(stats[k])
+			total += n
+		}
+		return total
+	}
+
+	p := Player{
+		Name:        stats["NAME"],
+		Champion:    stats["SKIN"],
+		Position:    stats["TEAM_POSITION"],
+		Win:         stats["WIN"] == "Win",
+		Level:       number("LEVEL"),
+		Kills:       number("CHAMPIONS_KILLED"),
+		Deaths:      number("NUM_DEATHS"),
+		Assists:     number("ASSISTS"),
+		CreepScore:  number("MINIONS_KILLED", "NEUTRAL_MINIONS_KILLED"),
+		Gold:        number("GOLD_EARNED"),
+		Damage:      number("TOTAL_DAMAGE_DEALT_TO_CHAMPIONS"),
+		VisionScore: number("VISION_SCORE"),
+		Stats:       stats,
+	}
+	if name := stats["RIOT_ID_GAME_NAME"]; name != "" {
+		p.Name = name
+		if tag := stats["RIOT_ID_TAG_LINE"]; tag != "" {
+			p.Name += "#" + tag
+		}
+	}
+	if p.Position == "" {
+		p.Position = stats["INDIVIDUAL_POSITION"]
+	}
+	switch stats["TEAM"] {
+	case "100":
+		p.Side = "Blue"
+	case "200":
+		p.Side = "Red"
+	}
+	return p
+}
+
+// Duration returns the length of the game.
+func (m *Metadata) Duration() time.Duration {
+	return time.Duration(m.GameLength) * time.Millisecond
+}
+
+// PlayerRows returns one row per player, matching PlayerHeaders.
+func (m *Metadata) PlayerRows() [][]string {
+	rows := make([][]string, len(m.Players))
+	for i, p := range m.Players {
+		result := "Loss"
+		if p.Win {
+			result = "Win"
+		}
+		rows[i] = []string{
+			p.Side,
+			p.Name,
+			p.Champion,
+			p.Position,
+			result,
+			strconv.Itoa(p.Level),
+			strconv.Itoa(p.Kills),
+			strconv.Itoa(p.Deaths),
+			strconv.Itoa(p.Assists),
+			strconv.Itoa(p.CreepScore),
+			strconv.Itoa(p.Gold),
+			strconv.Itoa(p.Damage),
+			strconv.Itoa(p.VisionScore),
+		}
+	}
+	return rows
+}
+
+// SidecarPath returns the path of the metadata file written next to a replay, e.g.
+// "2620066-1.json" for "2620066-1.rofl".
+func SidecarPath(replayPath string) string {
+	return strings.TrimSuffix(replayPath, filepath.Ext(replayPath)) + ".json"
+}
+
+// WriteSidecar writes the metadata of a replay to a JSON file next to it.
+//
+// Parameters:
+//   - replayPath: The path of the replay.
+//   - m: The metadata of the replay.
+//
+// Returns:
+//   - string: The path of the written file.
+//   - error: An error if the file cannot be written.
+func WriteSidecar(replayPath string, m *Metadata) (string, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error encoding replay metadata: %v", err)
+	}
+	path := SidecarPath(replayPath)
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("error writing replay metadata: %v", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,85 @@
+package rofl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testMetadata = `{"gameLength":1925000,"gameVersion":"14.9.584.6824","lastGameChunkId":65,"lastKeyFrameId":32,"statsJson":"[{\"RIOT_ID_GAME_NAME\":\"Faker\",\"RIOT_ID_TAG_LINE\":\"KR1\",\"SKIN\":\"Ahri\",\"TEAM\":\"100\",\"WIN\":\"Win\",\"CHAMPIONS_KILLED\":\"7\",\"NUM_DEATHS\":\"1\",\"ASSISTS\":\"9\",\"MINIONS_KILLED\":\"250\",\"NEUTRAL_MINIONS_KILLED\":\"12\",\"TEAM_POSITION\":\"MIDDLE\"},{\"NAME\":\"Chovy\",\"SKIN\":\"Azir\",\"TEAM\":\"200\",\"WIN\":\"Fail\"}]"}`
+
+// headerReplay builds a replay with the layout of older clients.
+func headerReplay() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("RIOT\x00\x00")
+	buf.Write(make([]byte, headerOffset-buf.Len()))
+	header := make([]byte, headerSize)
+	binary.LittleEndian.PutUint32(header[6:10], headerOffset+headerSize)
+	binary.LittleEndian.PutUint32(header[10:14], uint32(len(testMetadata)))
+	buf.Write(header)
+	buf.WriteString(testMetadata)
+	buf.WriteString("payload")
+	return buf.Bytes()
+}
+
+// trailerReplay builds a replay with the layout of newer clients.
+func trailerReplay() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("RIOT2\x00")
+	buf.Write(bytes.Repeat([]byte{0xff}, 300))
+	buf.WriteString(testMetadata)
+	length := make([]byte, 4)
+	binary.LittleEndian.PutUint32(length, uint32(len(testMetadata)))
+	buf.Write(length)
+	return buf.Bytes()
+}
+
+func TestParse(t *testing.T) {
+	for name, data := range map[string][]byte{"header": headerReplay(), "trailer": trailerReplay()} {
+		m, err := Parse(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("Failed to parse %s replay: %v", name, err)
+		}
+		if m.Patch != "14.9" || m.Duration() != 32*time.Minute+5*time.Second {
+			t.Fatalf("Unexpected metadata of %s replay: %+v", name, m)
+		}
+		if len(m.Players) != 2 {
+			t.Fatalf("Expected 2 players in %s replay, but got %d", name, len(m.Players))
+		}
+		p := m.Players[0]
+		if p.Name != "Faker#KR1" || p.Champion != "Ahri" || p.Side != "Blue" || !p.Win || p.CreepScore != 262 {
+			t.Fatalf("Unexpected player in %s replay: %+v", name, p)
+		}
+		if m.Players[1].Name != "Chovy" || m.Players[1].Win {
+			t.Fatalf("Unexpected player in %s replay: %+v", name, m.Players[1])
+		}
+	}
+
+	if _, err := Parse(bytes.NewReader([]byte("PK\x03\x04")), 4); err == nil {
+		t.Fatalf("Expected an error for a file that is not a replay, but got none")
+	}
+}
+
+func TestWriteSidecar(t *testing.T) {
+	replay := filepath.Join(t.TempDir(), "2620066-1.rofl")
+	if err := os.WriteFile(replay, headerReplay(), 0644); err != nil {
+		t.Fatalf("Failed to write replay: %v", err)
+	}
+	m, err := ParseFile(replay)
+	if err != nil {
+		t.Fatalf("Failed to parse replay: %v", err)
+	}
+	path, err := WriteSidecar(replay, m)
+	if err != nil {
+		t.Fatalf("Failed to write sidecar: %v", err)
+	}
+	if filepath.Base(path) != "2620066-1.json" {
+		t.Fatalf("Expected sidecar 2620066-1.json, but got %s", path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Expected sidecar to exist, but got %v", err)
+	}
+}
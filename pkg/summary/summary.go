@@ -0,0 +1,259 @@
+// Package summary builds post-match summaries of series from their events archives.
+//
+// A summary lists, for each game of a series, its duration, its winner, the final score,
+// the kills per team and per player and the objectives taken by each team.
+package summary
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+)
+
+// Summary is the post-match summary of a series.
+type Summary struct {
+	SeriesID string        // SeriesID is the ID of the series.
+	Teams    []TeamSummary // Teams are the teams of the series, with the number of games won as score.
+	Games    []GameSummary // Games are the summaries of the games played.
+}
+
+// GameSummary is the summary of a game.
+type GameSummary struct {
+	Number   int             // Number is the number of the game in the series.
+	ID       string          // ID is the unique identifier of the game.
+	Map      string          // Map is the name of the map, if any.
+	Duration time.Duration   // Duration is the duration of the game.
+	Finished bool            // Finished indicates whether the game has finished.
+	Winner   string          // Winner is the name of the winning team, if any.
+	Teams    []TeamSummary   // Teams are the teams playing the game.
+	Players  []PlayerSummary // Players are the players of both teams.
+}
+
+// TeamSummary is the summary of a team in a series or a game.
+type TeamSummary struct {
+	ID         string         // ID is the unique identifier of the team.
+	Name       string         // Name is the name of the team.
+	Won        bool           // Won indicates whether the team won.
+	Score      int            // Score is the final score of the team.
+	Kills      int            // Kills is the number of kills of the team.
+	Objectives map[string]int // Objectives counts the objectives taken by the team, by type.
+}
+
+// PlayerSummary is the summary of a player in a game.
+type PlayerSummary struct {
+	ID        string // ID is the unique identifier of the player.
+	TeamID    string // TeamID is the unique identifier of the team of the player.
+	Team      string // Team is the name of the team of the player.
+	Name      string // Name is the name of the player.
+	Character string // Character is the champion or agent played.
+	Kills     int    // Kills is the number of kills of the player.
+	Deaths    int    // Deaths is the number of deaths of the player.
+	Assists   int    // Assists is the number of assists of the player.
+}
+
+// ErrNoState is returned by Summarize when the events carry no series state.
+var ErrNoState = errors.New("no series state found in events")
+
+// TeamHeaders are the headers of the rows returned by TeamRows.
+var TeamHeaders = []string{"Game", "Map", "Duration", "Winner", "Team", "Score", "Kills", "Objectives"}
+
+// PlayerHeaders are the headers of the rows returned by PlayerRows.
+var PlayerHeaders = []string{"Game", "Team", "Player", "Character", "Kills", "Deaths", "Assists"}
+
+// SummarizeArchive builds the summary of the series stored in an events archive.
+//
+// Parameters:
+//   - path: The path of the events archive downloaded with graphql.DownloadJSON.
+//
+// Returns:
+//   - *Summary: The summary of the series.
+//   - error: An error if the archive cannot be read or holds no series state.
+func SummarizeArchive(path string) (*Summary, error) {
+	archive, err := events.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+	return Summarize(archive.Events())
+}
+
+// Summarize builds the summary of a series from its events.
+//
+// The final scores, kills and objectives are read from the last series state carried by the
+// events, while game durations are measured between the events starting and ending each game,
+// falling back to the in-game clock.
+//
+// Parameters:
+//   - seq: An iterator over the events of the series, in order.
+//
+// Returns:
+//   - *Summary: The summary of the series.
+//   - error: An error if the events cannot be read or hold no series state.
+func Summarize(seq iter.Seq2[events.Envelope, error]) (*Summary, error) {
+	var last events.Event
+	var seriesID string
+	starts := map[string]time.Time{}
+	ends := map[string]time.Time{}
+
+	for env, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		seriesID = env.SeriesID
+		if len(env.SeriesState) > 0 {
+			last = env.Event
+		}
+		switch env.Type {
+		case "series-started-game":
+			starts[env.Target.ID] = env.OccurredAt
+		case "series-ended-game":
+			ends[env.Target.ID] = env.OccurredAt
+		}
+	}
+
+	state, err := last.State()
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, ErrNoState
+	}
+
+	s := &Summary{SeriesID: seriesID}
+	if state.ID != "" {
+		s.SeriesID = state.ID
+	}
+	for _, t := range state.Teams {
+		s.Teams = append(s.Teams, TeamSummary{ID: t.ID, Name: t.Name, Won: t.Won, Score: t.Score, Kills: t.Kills})
+	}
+
+	for i, g := range state.Games {
+		if !g.Started {
+			continue
+		}
+		game := GameSummary{
+			Number:   g.SequenceNumber,
+			ID:       g.ID,
+			Map:      g.Map.Name,
+			Finished: g.Finished,
+			Duration: time.Duration(g.Clock.CurrentSeconds * float64(time.Second)),
+		}
+		if game.Number == 0 {
+			game.Number = i + 1
+		}
+		start, started := starts[g.ID]
+		end, ended := ends[g.ID]
+		if started && ended {
+			game.Duration = end.Sub(start)
+		}
+		if winner := g.Winner(); winner != nil {
+			game.Winner = winner.Name
+		}
+		for _, t := range g.Teams {
+			team := TeamSummary{ID: t.ID, Name: t.Name, Won: t.Won, Score: t.Score, Kills: t.Kills, Objectives: map[string]int{}}
+			for _, o := range t.Objectives {
+				team.Objectives[objectiveName(o)] += o.CompletionCount
+			}
+			game.Teams = append(game.Teams, team)
+			for _, p := range t.Players {
+				game.Players = append(game.Players, PlayerSummary{
+					ID:        p.ID,
+					TeamID:    t.ID,
+					Team:      t.Name,
+					Name:      p.Name,
+					Character: p.Character.Name,
+					Kills:     p.Kills,
+					Deaths:    p.Deaths,
+					Assists:   p.KillAssistsGiven,
+				})
+			}
+		}
+		s.Games = append(s.Games, game)
+	}
+
+	return s, nil
+}
+
+// objectiveName returns the name used to count an objective.
+func objectiveName(o events.ObjectiveState) string {
+	if o.Type != "" {
+		return o.Type
+	}
+	return o.ID
+}
+
+// TeamRows returns one row per team and game, matching TeamHeaders.
+func (s *Summary) TeamRows() [][]string {
+	var rows [][]string
+	for _, g := range s.Games {
+		for _, t := range g.Teams {
+			rows = append(rows, []string{
+				strconv.Itoa(g.Number),
+				g.Map,
+				FormatDuration(g.Duration),
+				g.Winner,
+				t.Name,
+				strconv.Itoa(t.Score),
+				strconv.Itoa(t.Kills),
+				FormatObjectives(t.Objectives),
+			})
+		}
+	}
+	return rows
+}
+
+// PlayerRows returns one row per player and game, matching PlayerHeaders.
+func (s *Summary) PlayerRows() [][]string {
+	var rows [][]string
+	for _, g := range s.Games {
+		for _, p := range g.Players {
+			rows = append(rows, []string{
+				strconv.Itoa(g.Number),
+				p.Team,
+				p.Name,
+				p.Character,
+				strconv.Itoa(p.Kills),
+				strconv.Itoa(p.Deaths),
+				strconv.Itoa(p.Assists),
+			})
+		}
+	}
+	return rows
+}
+
+// Score returns the series score, e.g. "Team 1 2 - Team 2 1".
+func (s *Summary) Score() string {
+	parts := make([]string, len(s.Teams))
+	for i, t := range s.Teams {
+		parts[i] = fmt.Sprintf("%s %d", t.Name, t.Score)
+	}
+	return strings.Join(parts, " - ")
+}
+
+// FormatDuration formats a duration as minutes and seconds, e.g. "32:05".
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// FormatObjectives formats objective counts sorted by type, e.g. "destroyTower 9, slayDragon 3".
+func FormatObjectives(objectives map[string]int) string {
+	names := make([]string, 0, len(objectives))
+	for name, count := range objectives {
+		if count > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s %d", name, objectives[name])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,42 @@
+package summary
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+)
+
+const testEvents = `{"id":"tx-1","sequenceNumber":1,"occurredAt":"2024-05-10T12:00:00Z","seriesId":"2620066","events":[{"id":"ev-1","type":"series-started-game","actor":{"type":"series","id":"2620066"},"target":{"type":"game","id":"g1"}}]}
+{"id":"tx-2","sequenceNumber":2,"occurredAt":"2024-05-10T12:31:30Z","seriesId":"2620066","events":[{"id":"ev-2","type":"series-ended-game","actor":{"type":"series","id":"2620066"},"target":{"type":"game","id":"g1"},"seriesState":{"id":"2620066","teams":[{"id":"t1","name":"Team 1","score":1},{"id":"t2","name":"Team 2","score":0}],"games":[{"id":"g1","sequenceNumber":1,"started":true,"finished":true,"clock":{"currentSeconds":1800},"teams":[{"id":"t1","name":"Team 1","won":true,"kills":20,"objectives":[{"id":"destroyTower","type":"destroyTower","completionCount":9},{"id":"slayDragon","type":"slayDragon","completionCount":3}],"players":[{"id":"p1","name":"Player 1","kills":12,"deaths":1,"killAssistsGiven":5,"character":{"name":"Ahri"}}]},{"id":"t2","name":"Team 2","kills":7,"players":[{"id":"p2","name":"Player 2","kills":7,"deaths":12,"character":{"name":"Zed"}}]}]},{"id":"g2","sequenceNumber":2,"started":false}]}}]}
+`
+
+func TestSummarize(t *testing.T) {
+	s, err := Summarize(events.Flatten(events.ReadTransactions(strings.NewReader(testEvents))))
+	if err != nil {
+		t.Fatalf("Failed to summarize events: %v", err)
+	}
+	if len(s.Games) != 1 {
+		t.Fatalf("Expected 1 game, but got %d", len(s.Games))
+	}
+	game := s.Games[0]
+	if game.Duration != 31*time.Minute+30*time.Second {
+		t.Fatalf("Expected duration to be measured between events, but got %s", game.Duration)
+	}
+	if game.Winner != "Team 1" {
+		t.Fatalf("Expected winner to be Team 1, but got %s", game.Winner)
+	}
+	if s.Score() != "Team 1 1 - Team 2 0" {
+		t.Fatalf("Unexpected series score %s", s.Score())
+	}
+
+	rows := s.TeamRows()
+	if len(rows) != 2 || rows[0][7] != "destroyTower 9, slayDragon 3" || rows[0][2] != "31:30" {
+		t.Fatalf("Unexpected team rows %v", rows)
+	}
+	players := s.PlayerRows()
+	if len(players) != 2 || players[0][2] != "Player 1" || players[0][6] != "5" {
+		t.Fatalf("Unexpected player rows %v", players)
+	}
+}
@@ -0,0 +1,237 @@
+// Package validate checks the integrity of GRID series events.
+//
+// The checks cover the continuity of transaction sequence numbers, the monotonicity of their
+// timestamps, duplicate transaction and event IDs, games that are started but never ended,
+// and schema anomalies such as missing identifiers or inconsistent event types.
+package validate
+
+import (
+	"fmt"
+	"iter"
+	"sort"
+	"strings"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+)
+
+// Names of the checks reported in issues.
+const (
+	CheckSequence       = "sequence"        // CheckSequence reports gaps and disorder in sequence numbers.
+	CheckTimestamp      = "timestamp"       // CheckTimestamp reports timestamps going back in time.
+	CheckDuplicateID    = "duplicate-id"    // CheckDuplicateID reports transaction or event IDs seen twice.
+	CheckUnfinishedGame = "unfinished-game" // CheckUnfinishedGame reports games started but never ended.
+	CheckSchema         = "schema"          // CheckSchema reports missing or inconsistent fields.
+	CheckDecode         = "decode"          // CheckDecode reports data that cannot be read.
+)
+
+// Issue is a single problem found in the events.
+type Issue struct {
+	Check          string `json:"check"`                    // Check is the name of the check that failed.
+	SequenceNumber int64  `json:"sequenceNumber,omitempty"` // SequenceNumber is the sequence number of the transaction, if any.
+	TransactionID  string `json:"transactionId,omitempty"`  // TransactionID is the ID of the transaction, if any.
+	EventID        string `json:"eventId,omitempty"`        // EventID is the ID of the event, if any.
+	Message        string `json:"message"`                  // Message describes the problem.
+}
+
+// Report is the result of the validation of a series' events.
+type Report struct {
+	File         string         `json:"file,omitempty"` // File is the path of the validated archive, if any.
+	SeriesID     string         `json:"seriesId"`       // SeriesID is the ID of the series.
+	Transactions int            `json:"transactions"`   // Transactions is the number of transactions read.
+	Events       int            `json:"events"`         // Events is the number of events read.
+	Games        int            `json:"games"`          // Games is the number of games started.
+	Valid        bool           `json:"valid"`          // Valid indicates whether no issue was found.
+	Counts       map[string]int `json:"counts"`         // Counts is the number of issues found per check.
+	Issues       []Issue        `json:"issues"`         // Issues are the issues found, up to the configured maximum.
+	Truncated    bool           `json:"truncated"`      // Truncated indicates whether issues were left out of Issues.
+}
+
+// Options configures a validation.
+type Options struct {
+	MaxIssues int // MaxIssues is the maximum number of issues listed in the report; 0 lists them all.
+}
+
+// validator accumulates the state of a validation.
+type validator struct {
+	opts    Options
+	report  *Report
+	lastSeq int64
+	lastTx  events.Transaction
+	seenTx  map[string]struct{}
+	seenEv  map[string]struct{}
+	started map[string]int64
+	ended   map[string]struct{}
+}
+
+// ValidateArchive validates the events stored in an events archive.
+//
+// Parameters:
+//   - path: The path of the events archive downloaded with graphql.DownloadJSON.
+//   - opts: The validation options.
+//
+// Returns:
+//   - *Report: The validation report.
+//   - error: An error if the archive cannot be opened.
+func ValidateArchive(path string, opts Options) (*Report, error) {
+	archive, err := events.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	report := Validate(archive.Transactions(), opts)
+	report.File = path
+	return report, nil
+}
+
+// Validate validates a sequence of transactions.
+//
+// Errors yielded by the sequence are reported as decode issues, so that truncated or
+// corrupted files produce a report instead of failing.
+//
+// Parameters:
+//   - transactions: An iterator over the transactions of a series, in file order.
+//   - opts: The validation options.
+//
+// Returns:
+//   - *Report: The validation report.
+func Validate(transactions iter.Seq2[events.Transaction, error], opts Options) *Report {
+	v := &validator{
+		opts:    opts,
+		report:  &Report{Counts: map[string]int{}, Issues: []Issue{}},
+		seenTx:  map[string]struct{}{},
+		seenEv:  map[string]struct{}{},
+		started: map[string]int64{},
+		ended:   map[string]struct{}{},
+	}
+
+	for tx, err := range transactions {
+		if err != nil {
+			v.add(Issue{Check: CheckDecode, Message: err.Error()})
+			break
+		}
+		v.transaction(tx)
+	}
+
+	var unfinished []string
+	for gameID := range v.started {
+		if _, ok := v.ended[gameID]; !ok {
+			unfinished = append(unfinished, gameID)
+		}
+	}
+	sort.Slice(unfinished, func(i, j int) bool { return v.started[unfinished[i]] < v.started[unfinished[j]] })
+	for _, gameID := range unfinished {
+		v.add(Issue{Check: CheckUnfinishedGame, SequenceNumber: v.started[gameID], Message: fmt.Sprintf("game %s started but never ended", gameID)})
+	}
+
+	v.report.Games = len(v.started)
+	v.report.Valid = len(v.report.Counts) == 0
+	return v.report
+}
+
+// transaction checks a transaction and its events.
+func (v *validator) transaction(tx events.Transaction) {
+	r := v.report
+	r.Transactions++
+	issue := func(check, format string, args ...any) {
+		v.add(Issue{Check: check, SequenceNumber: tx.SequenceNumber, TransactionID: tx.ID, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if tx.ID == "" {
+		issue(CheckSchema, "transaction has no ID")
+	} else if _, ok := v.seenTx[tx.ID]; ok {
+		issue(CheckDuplicateID, "transaction ID %s seen more than once", tx.ID)
+	} else {
+		v.seenTx[tx.ID] = struct{}{}
+	}
+
+	if tx.SeriesID == "" {
+		issue(CheckSchema, "transaction has no series ID")
+	} else if r.SeriesID == "" {
+		r.SeriesID = tx.SeriesID
+	} else if tx.SeriesID != r.SeriesID {
+		issue(CheckSchema, "transaction belongs to series %s instead of %s", tx.SeriesID, r.SeriesID)
+	}
+
+	if tx.OccurredAt.IsZero() {
+		issue(CheckSchema, "transaction has no occurredAt timestamp")
+	} else if r.Transactions > 1 && tx.OccurredAt.Before(v.lastTx.OccurredAt) {
+		issue(CheckTimestamp, "occurredAt %s is before %s of transaction %d",
+			tx.OccurredAt.Format("2006-01-02T15:04:05.000Z07:00"), v.lastTx.OccurredAt.Format("2006-01-02T15:04:05.000Z07:00"), v.lastTx.SequenceNumber)
+	}
+
+	if r.Transactions > 1 {
+		switch {
+		case tx.SequenceNumber == v.lastSeq:
+			issue(CheckSequence, "sequence number %d repeated", tx.SequenceNumber)
+		case tx.SequenceNumber < v.lastSeq:
+			issue(CheckSequence, "sequence number %d follows %d", tx.SequenceNumber, v.lastSeq)
+		case tx.SequenceNumber > v.lastSeq+1:
+			issue(CheckSequence, "sequence numbers %d to %d are missing", v.lastSeq+1, tx.SequenceNumber-1)
+		}
+	}
+	if tx.SequenceNumber > v.lastSeq || r.Transactions == 1 {
+		v.lastSeq = tx.SequenceNumber
+	}
+
+	if len(tx.Events) == 0 {
+		issue(CheckSchema, "transaction has no events")
+	}
+	for _, e := range tx.Events {
+		v.event(tx, e)
+	}
+
+	if !tx.OccurredAt.IsZero() {
+		v.lastTx = tx
+	}
+}
+
+// event checks an event of a transaction.
+func (v *validator) event(tx events.Transaction, e events.Event) {
+	v.report.Events++
+	issue := func(check, format string, args ...any) {
+		v.add(Issue{Check: check, SequenceNumber: tx.SequenceNumber, TransactionID: tx.ID, EventID: e.ID, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if e.ID == "" {
+		issue(CheckSchema, "event has no ID")
+	} else if _, ok := v.seenEv[e.ID]; ok {
+		issue(CheckDuplicateID, "event ID %s seen more than once", e.ID)
+	} else {
+		v.seenEv[e.ID] = struct{}{}
+	}
+
+	if e.Type == "" {
+		issue(CheckSchema, "event has no type")
+	} else if e.Actor.Type != "" && e.Action != "" && e.Target.Type != "" {
+		if expected := strings.Join([]string{e.Actor.Type, e.Action, e.Target.Type}, "-"); expected != e.Type {
+			issue(CheckSchema, "event type %s does not match actor, action and target %s", e.Type, expected)
+		}
+	}
+
+	switch e.Type {
+	case "series-started-game":
+		if e.Target.ID == "" {
+			issue(CheckSchema, "%s event has no target game ID", e.Type)
+		} else if _, ok := v.started[e.Target.ID]; !ok {
+			v.started[e.Target.ID] = tx.SequenceNumber
+		}
+	case "series-ended-game":
+		if e.Target.ID == "" {
+			issue(CheckSchema, "%s event has no target game ID", e.Type)
+		} else {
+			v.ended[e.Target.ID] = struct{}{}
+		}
+	}
+}
+
+// add records an issue, listing it in the report unless the maximum is reached.
+func (v *validator) add(issue Issue) {
+	r := v.report
+	r.Counts[issue.Check]++
+	if v.opts.MaxIssues > 0 && len(r.Issues) >= v.opts.MaxIssues {
+		r.Truncated = true
+		return
+	}
+	r.Issues = append(r.Issues, issue)
+}
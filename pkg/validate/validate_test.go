@@ -0,0 +1,47 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+)
+
+func TestValidateValid(t *testing.T) {
+	input := `{"id":"tx-1","sequenceNumber":1,"occurredAt":"2024-05-10T12:00:00Z","seriesId":"1","events":[{"id":"ev-1","type":"series-started-game","action":"started","actor":{"type":"series","id":"1"},"target":{"type":"game","id":"g1"}}]}
+{"id":"tx-2","sequenceNumber":2,"occurredAt":"2024-05-10T12:30:00Z","seriesId":"1","events":[{"id":"ev-2","type":"series-ended-game","action":"ended","actor":{"type":"series","id":"1"},"target":{"type":"game","id":"g1"}}]}
+`
+	report := Validate(events.ReadTransactions(strings.NewReader(input)), Options{})
+	if !report.Valid {
+		t.Fatalf("Expected events to be valid, but got issues %+v", report.Issues)
+	}
+	if report.Transactions != 2 || report.Events != 2 || report.Games != 1 {
+		t.Fatalf("Unexpected counts in report %+v", report)
+	}
+}
+
+func TestValidateIssues(t *testing.T) {
+	input := `{"id":"tx-1","sequenceNumber":1,"occurredAt":"2024-05-10T12:00:00Z","seriesId":"1","events":[{"id":"ev-1","type":"series-started-game","action":"started","actor":{"type":"series","id":"1"},"target":{"type":"game","id":"g1"}}]}
+{"id":"tx-2","sequenceNumber":4,"occurredAt":"2024-05-10T11:59:00Z","seriesId":"1","events":[{"id":"ev-1","type":"player-killed-player","action":"killed","actor":{"type":"player","id":"p1"},"target":{"type":"team","id":"t2"}}]}
+{"id":"tx-3","sequenceNumber":5,"occurredAt":"2024-05-10T12:01:00Z","seriesId":"1","events":[]}
+`
+	report := Validate(events.ReadTransactions(strings.NewReader(input)), Options{MaxIssues: 2})
+	if report.Valid {
+		t.Fatalf("Expected events to be invalid")
+	}
+	expected := map[string]int{
+		CheckSequence:       1,
+		CheckTimestamp:      1,
+		CheckDuplicateID:    1,
+		CheckSchema:         2,
+		CheckUnfinishedGame: 1,
+	}
+	for check, count := range expected {
+		if report.Counts[check] != count {
+			t.Fatalf("Expected %d %s issues, but got %d (%+v)", count, check, report.Counts[check], report.Counts)
+		}
+	}
+	if len(report.Issues) != 2 || !report.Truncated {
+		t.Fatalf("Expected issues to be truncated to 2, but got %d", len(report.Issues))
+	}
+}
@@ -0,0 +1,84 @@
+package warehouse
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/graphql"
+)
+
+// AddResults sets the result of the series whose events archive has been ingested.
+//
+// The result of other series is left unchanged.
+//
+// Parameters:
+//   - series: The series to complete, updated in place.
+//
+// Returns:
+//   - error: An error if the results cannot be read.
+func (w *Warehouse) AddResults(series []graphql.Series) error {
+	stmt, err := w.db.Prepare(`SELECT st.team_id, t.name, st.score, COALESCE(st.won, 0) FROM series_teams st
+		JOIN teams t ON t.id = st.team_id
+		WHERE st.series_id = ? AND st.score IS NOT NULL`)
+	if err != nil {
+		return fmt.Errorf("error preparing results query: %v", err)
+	}
+	defer stmt.Close()
+
+	for i := range series {
+		rows, err := stmt.Query(series[i].ID)
+		if err != nil {
+			return fmt.Errorf("error reading results: %v", err)
+		}
+		var result *graphql.Result
+		for rows.Next() {
+			var teamID, name string
+			var score int
+			var won bool
+			if err := rows.Scan(&teamID, &name, &score, &won); err != nil {
+				rows.Close()
+				return fmt.Errorf("error reading result row: %v", err)
+			}
+			if result == nil {
+				result = &graphql.Result{Scores: map[string]int{}}
+			}
+			result.Scores[teamID] = score
+			if won {
+				result.Winner = name
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return fmt.Errorf("error reading results: %v", err)
+		}
+		if result != nil {
+			series[i].Result = result
+		}
+	}
+	return nil
+}
+
+// LoadResults sets the result of the series from the warehouse in the configuration
+// directory, if it exists. No warehouse is created otherwise.
+//
+// Parameters:
+//   - series: The series to complete, updated in place.
+//
+// Returns:
+//   - error: An error if the warehouse cannot be opened or read.
+func LoadResults(series []graphql.Series) error {
+	path, err := DefaultPath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	w, err := Open(path)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	return w.AddResults(series)
+}
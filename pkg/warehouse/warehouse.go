@@ -0,0 +1,448 @@
+// Package warehouse maintains a local SQLite database of series metadata and events.
+//
+// The warehouse holds the series returned by the GRID API together with the games, teams,
+// players and events read from the downloaded events archives, so that many series can be
+// queried at once with SQL. Ingestion is idempotent: ingesting the same series again
+// replaces its previous data.
+package warehouse
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+	"path/filepath"
+	"time"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/config"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/graphql"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/library"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/summary"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// databaseName is the file name of the default warehouse inside the configuration directory.
+const databaseName = "warehouse.db"
+
+// schema creates the tables of the warehouse.
+const schema = `
+CREATE TABLE IF NOT EXISTS series (
+	id                   TEXT PRIMARY KEY,
+	title_id             TEXT NOT NULL DEFAULT '',
+	tournament_id        TEXT NOT NULL DEFAULT '',
+	tournament_name      TEXT NOT NULL DEFAULT '',
+	tournament_short     TEXT NOT NULL DEFAULT '',
+	format               TEXT NOT NULL DEFAULT '',
+	start_time_scheduled TEXT NOT NULL DEFAULT '',
+	updated_at           TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS teams (
+	id   TEXT PRIMARY KEY,
+	name TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS series_teams (
+	series_id TEXT NOT NULL REFERENCES series(id),
+	team_id   TEXT NOT NULL REFERENCES teams(id),
+	position  INTEGER NOT NULL,
+	score     INTEGER,
+	won       INTEGER,
+	PRIMARY KEY (series_id, team_id)
+);
+CREATE TABLE IF NOT EXISTS games (
+	id               TEXT PRIMARY KEY,
+	series_id        TEXT NOT NULL REFERENCES series(id),
+	sequence_number  INTEGER NOT NULL,
+	map              TEXT NOT NULL DEFAULT '',
+	finished         INTEGER NOT NULL,
+	duration_seconds REAL NOT NULL,
+	winner_team_id   TEXT
+);
+CREATE TABLE IF NOT EXISTS game_teams (
+	game_id TEXT NOT NULL REFERENCES games(id),
+	team_id TEXT NOT NULL REFERENCES teams(id),
+	won     INTEGER NOT NULL,
+	score   INTEGER NOT NULL,
+	kills   INTEGER NOT NULL,
+	PRIMARY KEY (game_id, team_id)
+);
+CREATE TABLE IF NOT EXISTS players (
+	id   TEXT PRIMARY KEY,
+	name TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS game_players (
+	game_id   TEXT NOT NULL REFERENCES games(id),
+	player_id TEXT NOT NULL REFERENCES players(id),
+	team_id   TEXT NOT NULL,
+	character TEXT NOT NULL DEFAULT '',
+	kills     INTEGER NOT NULL,
+	deaths    INTEGER NOT NULL,
+	assists   INTEGER NOT NULL,
+	PRIMARY KEY (game_id, player_id)
+);
+CREATE TABLE IF NOT EXISTS events (
+	series_id          TEXT NOT NULL,
+	sequence_number    INTEGER NOT NULL,
+	event_index        INTEGER NOT NULL,
+	transaction_id     TEXT NOT NULL,
+	event_id           TEXT NOT NULL,
+	occurred_at        TEXT NOT NULL,
+	game_id            TEXT,
+	type               TEXT NOT NULL,
+	actor_type         TEXT NOT NULL,
+	actor_id           TEXT NOT NULL,
+	action             TEXT NOT NULL,
+	target_type        TEXT NOT NULL,
+	target_id          TEXT NOT NULL,
+	actor_state_delta  TEXT,
+	target_state_delta TEXT,
+	series_state_delta TEXT,
+	PRIMARY KEY (series_id, sequence_number, event_index)
+);
+CREATE INDEX IF NOT EXISTS events_type ON events (series_id, type);
+CREATE INDEX IF NOT EXISTS events_game ON events (game_id);
+`
+
+// Warehouse is an open warehouse database.
+type Warehouse struct {
+	db *sql.DB
+}
+
+// DefaultPath returns the path of the warehouse in the configuration directory.
+func DefaultPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", fmt.Errorf("error getting configuration directory: %v", err)
+	}
+	return filepath.Join(dir, databaseName), nil
+}
+
+// Open opens the warehouse at the given path, creating it and its tables if needed.
+//
+// Parameters:
+//   - path: The path of the SQLite database file.
+//
+// Returns:
+//   - *Warehouse: The opened warehouse. It must be closed after use.
+//   - error: An error if the database cannot be opened or initialized.
+func Open(path string) (*Warehouse, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening warehouse: %v", err)
+	}
+	// SQLite allows a single writer; a single connection avoids "database is locked" errors.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("PRAGMA foreign_keys = ON; PRAGMA journal_mode = WAL;"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error configuring warehouse: %v", err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating warehouse tables: %v", err)
+	}
+	return &Warehouse{db: db}, nil
+}
+
+// Close closes the warehouse.
+func (w *Warehouse) Close() error {
+	return w.db.Close()
+}
+
+// DB returns the underlying database, e.g. to run queries.
+func (w *Warehouse) DB() *sql.DB {
+	return w.db
+}
+
+// IngestSeries upserts series metadata returned by the GRID API.
+//
+// Parameters:
+//   - titleID: The GRID title ID the series were queried for.
+//   - series: The series to upsert.
+//
+// Returns:
+//   - error: An error if the series cannot be written.
+func (w *Warehouse) IngestSeries(titleID string, series []graphql.Series) error {
+	tx, err := w.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, s := range series {
+		_, err := tx.Exec(`INSERT INTO series (id, title_id, tournament_id, tournament_name, tournament_short, format, start_time_scheduled, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET
+				title_id = excluded.title_id,
+				tournament_id = excluded.tournament_id,
+				tournament_name = excluded.tournament_name,
+				tournament_short = excluded.tournament_short,
+				format = excluded.format,
+				start_time_scheduled = excluded.start_time_scheduled,
+				updated_at = excluded.updated_at`,
+			s.ID, titleID, s.Tournament.ID, s.Tournament.Name, s.Tournament.NameShortened, s.Format.NameShortened, s.StartTimeScheduled, now)
+		if err != nil {
+			return fmt.Errorf("error upserting series %s: %v", s.ID, err)
+		}
+		for i, t := range s.Teams {
+			if err := upsertTeam(tx, t.BaseInfo.ID, t.BaseInfo.Name); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`INSERT INTO series_teams (series_id, team_id, position) VALUES (?, ?, ?)
+				ON CONFLICT (series_id, team_id) DO UPDATE SET position = excluded.position`, s.ID, t.BaseInfo.ID, i+1)
+			if err != nil {
+				return fmt.Errorf("error upserting teams of series %s: %v", s.ID, err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// IngestArchive loads the events of a series archive, with its games, teams and players.
+//
+// The previous events and games of the series are replaced. Metadata known from the library
+// is used for the series row when the series has not been ingested from the API.
+//
+// Parameters:
+//   - path: The path of the events archive downloaded with graphql.DownloadJSON.
+//   - meta: The metadata of the series recorded in the library, if any.
+//
+// Returns:
+//   - int: The number of events ingested.
+//   - error: An error if the archive cannot be read or the data cannot be written.
+func (w *Warehouse) IngestArchive(path string, meta library.Series) (int, error) {
+	archive, err := events.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer archive.Close()
+	return w.IngestEvents(archive.Events(), meta)
+}
+
+// IngestEvents loads a sequence of events of a series, with its games, teams and players.
+//
+// It behaves like IngestArchive.
+func (w *Warehouse) IngestEvents(seq iter.Seq2[events.Envelope, error], meta library.Series) (int, error) {
+	tx, err := w.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	insert, err := tx.Prepare(`INSERT OR REPLACE INTO events (series_id, sequence_number, event_index, transaction_id, event_id, occurred_at,
+		game_id, type, actor_type, actor_id, action, target_type, target_id, actor_state_delta, target_state_delta, series_state_delta)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, fmt.Errorf("error preparing events insert: %v", err)
+	}
+	defer insert.Close()
+
+	var seriesID, gameID string
+	var insertErr error
+	count := 0
+	cleared := false
+
+	// The events are inserted while they are summarized, so that the archive is read once.
+	s, err := summary.Summarize(func(yield func(events.Envelope, error) bool) {
+		for env, err := range seq {
+			if err == nil {
+				if !cleared {
+					seriesID = env.SeriesID
+					if seriesID == "" {
+						seriesID = meta.ID
+					}
+					if insertErr = clearSeries(tx, seriesID); insertErr != nil {
+						return
+					}
+					cleared = true
+				}
+				if env.Type == "series-started-game" {
+					gameID = env.Target.ID
+				}
+				_, insertErr = insert.Exec(seriesID, env.SequenceNumber, env.Index, env.TransactionID, env.ID,
+					env.OccurredAt.UTC().Format(time.RFC3339Nano), nullString(gameID), env.Type,
+					env.Actor.Type, env.Actor.ID, env.Action, env.Target.Type, env.Target.ID,
+					nullString(string(env.Actor.StateDelta)), nullString(string(env.Target.StateDelta)), nullString(string(env.SeriesStateDelta)))
+				if insertErr != nil {
+					insertErr = fmt.Errorf("error inserting event %s: %v", env.ID, insertErr)
+					return
+				}
+				count++
+				if env.Type == "series-ended-game" {
+					gameID = ""
+				}
+			}
+			if !yield(env, err) {
+				return
+			}
+		}
+	})
+	if insertErr != nil {
+		return 0, insertErr
+	}
+	if errors.Is(err, summary.ErrNoState) {
+		s = &summary.Summary{}
+	} else if err != nil {
+		return 0, err
+	}
+	if seriesID == "" {
+		seriesID = meta.ID
+	}
+	if seriesID == "" {
+		return 0, fmt.Errorf("no events found and no series ID known")
+	}
+
+	if err := upsertArchiveSeries(tx, seriesID, meta); err != nil {
+		return 0, err
+	}
+	if err := insertSummary(tx, seriesID, s); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing ingestion: %v", err)
+	}
+	return count, nil
+}
+
+// clearSeries removes the events and games previously ingested for a series.
+func clearSeries(tx *sql.Tx, seriesID string) error {
+	statements := []string{
+		`DELETE FROM game_players WHERE game_id IN (SELECT id FROM games WHERE series_id = ?)`,
+		`DELETE FROM game_teams WHERE game_id IN (SELECT id FROM games WHERE series_id = ?)`,
+		`DELETE FROM games WHERE series_id = ?`,
+		`DELETE FROM events WHERE series_id = ?`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement, seriesID); err != nil {
+			return fmt.Errorf("error clearing series %s: %v", seriesID, err)
+		}
+	}
+	return nil
+}
+
+// upsertArchiveSeries makes sure the series of an archive exists, filling metadata from the
+// library without overwriting metadata ingested from the API.
+func upsertArchiveSeries(tx *sql.Tx, seriesID string, meta library.Series) error {
+	_, err := tx.Exec(`INSERT INTO series (id, title_id, tournament_name, start_time_scheduled, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			title_id = CASE WHEN series.title_id = '' THEN excluded.title_id ELSE series.title_id END,
+			tournament_name = CASE WHEN series.tournament_name = '' THEN excluded.tournament_name ELSE series.tournament_name END,
+			start_time_scheduled = CASE WHEN series.start_time_scheduled = '' THEN excluded.start_time_scheduled ELSE series.start_time_scheduled END,
+			updated_at = excluded.updated_at`,
+		seriesID, meta.TitleID, meta.Tournament, meta.StartTime, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("error upserting series %s: %v", seriesID, err)
+	}
+	return nil
+}
+
+// insertSummary writes the games, teams and players of a series summary.
+func insertSummary(tx *sql.Tx, seriesID string, s *summary.Summary) error {
+	for i, t := range s.Teams {
+		if err := upsertTeam(tx, t.ID, t.Name); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`INSERT INTO series_teams (series_id, team_id, position, score, won) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (series_id, team_id) DO UPDATE SET score = excluded.score, won = excluded.won`,
+			seriesID, t.ID, i+1, t.Score, t.Won)
+		if err != nil {
+			return fmt.Errorf("error upserting teams of series %s: %v", seriesID, err)
+		}
+	}
+
+	for _, g := range s.Games {
+		var winnerID sql.NullString
+		for _, t := range g.Teams {
+			if t.Won {
+				winnerID = sql.NullString{String: t.ID, Valid: true}
+			}
+		}
+		_, err := tx.Exec(`INSERT OR REPLACE INTO games (id, series_id, sequence_number, map, finished, duration_seconds, winner_team_id)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`, g.ID, seriesID, g.Number, g.Map, g.Finished, g.Duration.Seconds(), winnerID)
+		if err != nil {
+			return fmt.Errorf("error inserting game %s: %v", g.ID, err)
+		}
+		for _, t := range g.Teams {
+			if err := upsertTeam(tx, t.ID, t.Name); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`INSERT OR REPLACE INTO game_teams (game_id, team_id, won, score, kills) VALUES (?, ?, ?, ?, ?)`,
+				g.ID, t.ID, t.Won, t.Score, t.Kills)
+			if err != nil {
+				return fmt.Errorf("error inserting team %s of game %s: %v", t.ID, g.ID, err)
+			}
+		}
+		for _, p := range g.Players {
+			_, err := tx.Exec(`INSERT INTO players (id, name) VALUES (?, ?) ON CONFLICT (id) DO UPDATE SET name = excluded.name`, p.ID, p.Name)
+			if err != nil {
+				return fmt.Errorf("error upserting player %s: %v", p.ID, err)
+			}
+			_, err = tx.Exec(`INSERT OR REPLACE INTO game_players (game_id, player_id, team_id, character, kills, deaths, assists) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				g.ID, p.ID, p.TeamID, p.Character, p.Kills, p.Deaths, p.Assists)
+			if err != nil {
+				return fmt.Errorf("error inserting player %s of game %s: %v", p.ID, g.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// upsertTeam inserts a team or updates its name.
+func upsertTeam(tx *sql.Tx, id, name string) error {
+	_, err := tx.Exec(`INSERT INTO teams (id, name) VALUES (?, ?) ON CONFLICT (id) DO UPDATE SET name = excluded.name`, id, name)
+	if err != nil {
+		return fmt.Errorf("error upserting team %s: %v", id, err)
+	}
+	return nil
+}
+
+// Query runs a SQL query and returns its result as text.
+//
+// Parameters:
+//   - query: The SQL query to run.
+//   - args: The arguments of the query placeholders.
+//
+// Returns:
+//   - []string: The names of the result columns.
+//   - [][]string: The result rows, with NULL values as empty strings.
+//   - error: An error if the query fails.
+func (w *Warehouse) Query(query string, args ...any) ([]string, [][]string, error) {
+	rows, err := w.db.Query(query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error running query: %v", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading columns: %v", err)
+	}
+
+	var result [][]string
+	values := make([]sql.NullString, len(columns))
+	pointers := make([]any, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, nil, fmt.Errorf("error reading row: %v", err)
+		}
+		row := make([]string, len(columns))
+		for i, v := range values {
+			row[i] = v.String
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error reading rows: %v", err)
+	}
+	return columns, result, nil
+}
+
+// nullString converts an empty string to NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,70 @@
+package warehouse
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/events"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/graphql"
+	"github.com/simplesmentemat/stealth-grid-cli/pkg/library"
+)
+
+const testEvents = `{"id":"tx-1","sequenceNumber":1,"occurredAt":"2024-05-10T12:00:00Z","seriesId":"2620066","events":[{"id":"ev-1","type":"series-started-game","actor":{"type":"series","id":"2620066"},"target":{"type":"game","id":"g1"}}]}
+{"id":"tx-2","sequenceNumber":2,"occurredAt":"2024-05-10T12:31:30Z","seriesId":"2620066","events":[{"id":"ev-2","type":"series-ended-game","actor":{"type":"series","id":"2620066"},"target":{"type":"game","id":"g1"},"seriesState":{"id":"2620066","teams":[{"id":"t1","name":"Team 1","score":1,"won":true},{"id":"t2","name":"Team 2","score":0}],"games":[{"id":"g1","sequenceNumber":1,"started":true,"finished":true,"teams":[{"id":"t1","name":"Team 1","won":true,"kills":20,"players":[{"id":"p1","name":"Player 1","kills":12}]},{"id":"t2","name":"Team 2","kills":7,"players":[{"id":"p2","name":"Player 2","kills":7}]}]}]}}]}
+`
+
+func TestIngest(t *testing.T) {
+	w, err := Open(filepath.Join(t.TempDir(), "warehouse.db"))
+	if err != nil {
+		t.Fatalf("Failed to open warehouse: %v", err)
+	}
+	defer w.Close()
+
+	series := []graphql.Series{{
+		ID:                 "2620066",
+		StartTimeScheduled: "2024-05-10T12:00:00Z",
+		Tournament:         graphql.Tournament{ID: "77", Name: "Tournament 1"},
+		Format:             graphql.Format{NameShortened: "Bo1"},
+		Teams:              []graphql.SeriesTeam{{BaseInfo: graphql.TeamInfo{ID: "t1", Name: "Team 1"}}, {BaseInfo: graphql.TeamInfo{ID: "t2", Name: "Team 2"}}},
+	}}
+	if err := w.IngestSeries("3", series); err != nil {
+		t.Fatalf("Failed to ingest series: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		seq := events.Flatten(events.ReadTransactions(strings.NewReader(testEvents)))
+		count, err := w.IngestEvents(seq, library.Series{ID: "2620066", Tournament: "Other name"})
+		if err != nil {
+			t.Fatalf("Failed to ingest events: %v", err)
+		}
+		if count != 2 {
+			t.Fatalf("Expected 2 events, but got %d", count)
+		}
+	}
+
+	columns, rows, err := w.Query(`SELECT s.tournament_name, s.format, (SELECT COUNT(*) FROM events), g.winner_team_id, p.name, gp.kills
+		FROM series s JOIN games g ON g.series_id = s.id JOIN game_players gp ON gp.game_id = g.id JOIN players p ON p.id = gp.player_id
+		ORDER BY gp.kills DESC`)
+	if err != nil {
+		t.Fatalf("Failed to query warehouse: %v", err)
+	}
+	if len(columns) != 6 || len(rows) != 2 {
+		t.Fatalf("Expected 2 rows of 6 columns, but got %v", rows)
+	}
+	expected := []string{"Tournament 1", "Bo1", "2", "t1", "Player 1", "12"}
+	if strings.Join(rows[0], "|") != strings.Join(expected, "|") {
+		t.Fatalf("Expected first row %v, but got %v", expected, rows[0])
+	}
+
+	series = append(series, graphql.Series{ID: "1"})
+	if err := w.AddResults(series); err != nil {
+		t.Fatalf("Failed to add results: %v", err)
+	}
+	if r := series[0].Result; r == nil || r.Scores["t1"] != 1 || r.Scores["t2"] != 0 || r.Winner != "Team 1" {
+		t.Fatalf("Unexpected result %+v", r)
+	}
+	if series[1].Result != nil {
+		t.Fatalf("Expected no result for a series without events, but got %+v", series[1].Result)
+	}
+}